@@ -0,0 +1,129 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+)
+
+// ARG_MAX_HEADROOM is the space reserved for the environment changes done by the system (as in POSIX xargs)
+const ARG_MAX_HEADROOM = 2048
+
+// MAX_ARG_STRLEN is the maximum size of a single argument (or environment string) on Linux,
+// including the terminating NUL
+const MAX_ARG_STRLEN = 131072
+
+// ArgMax returns the maximum size in bytes of the arguments and environment of a new process for the current platform
+// (on Windows, the maximum length of the command line, that doesn't include the environment).
+// The value can be overridden with the ARG_MAX environment variable.
+func ArgMax() int {
+	return argMax(runtime.GOOS)
+}
+
+func argMax(goos string) int {
+	if v, err := strconv.Atoi(os.Getenv("ARG_MAX")); err == nil && v > 0 {
+		return v
+	}
+
+	switch goos {
+	case "linux", "android":
+		return 2097152 // 1/4 of the default 8MB stack
+	case "darwin", "ios":
+		return 1048576
+	case "windows":
+		return 32767 // maximum command line length
+	case "freebsd", "netbsd", "openbsd", "dragonfly":
+		return 262144
+	}
+
+	return 131072 // historical Linux value
+}
+
+// SplitCommand splits a command with a (possibly long) list of items into multiple invocations that fit
+// in ArgMax, considering the size of the environment env (use os.Environ() for the current environment).
+// Each invocation contains the command followed by some of the items, in order.
+//
+// On Windows the limit is on the command line, with the arguments quoted as by os/exec and separated by spaces,
+// and the environment is not counted. On Linux each argument is also limited to MAX_ARG_STRLEN.
+func SplitCommand(command, items, env []string) ([][]string, error) {
+	return splitCommand(runtime.GOOS, command, items, env)
+}
+
+func splitCommand(goos string, command, items, env []string) ([][]string, error) {
+	var b Batcher
+
+	if goos == "windows" {
+		b = Batcher{Command: command, MaxBytes: argMax(goos), ArgSize: windowsArgSize}
+	} else {
+		ptr := strconv.IntSize / 8
+
+		b = Batcher{Command: command, ArgOverhead: ptr}
+		b.MaxBytes = argMax(goos) - ARG_MAX_HEADROOM - 2*ptr // argv and envp NULL terminators
+
+		if goos == "linux" || goos == "android" {
+			b.MaxArgBytes = MAX_ARG_STRLEN
+		}
+
+		for _, e := range env {
+			if b.MaxArgBytes > 0 && len(e)+1 > b.MaxArgBytes {
+				return nil, wrapDetail(ErrArgTooLong, strconv.Itoa(len(e))+" bytes")
+			}
+
+			b.MaxBytes -= b.argSize(e)
+		}
+	}
+
+	for _, arg := range command {
+		if b.MaxArgBytes > 0 && len(arg)+1 > b.MaxArgBytes {
+			return nil, wrapDetail(ErrArgTooLong, strconv.Itoa(len(arg))+" bytes")
+		}
+	}
+
+	if b.MaxBytes <= b.commandSize() {
+		return nil, ErrArgTooLong
+	}
+
+	return b.Batches(items)
+}
+
+// windowsArgSize returns the length of the argument in a Windows command line, in UTF-16 characters:
+// quoted as by os/exec (see syscall.EscapeArg), followed by a space (or the terminating NUL)
+func windowsArgSize(arg string) int {
+	if arg == "" {
+		return 3 // ""
+	}
+
+	size := 1
+	quote := false   // the argument is quoted
+	backslashes := 0 // the number of backslashes before the current character
+
+	for _, c := range arg {
+		switch c {
+		case ' ', '\t':
+			quote = true
+
+		case '"':
+			size += backslashes + 1 // the backslashes and the quote are escaped
+		}
+
+		if c == '\\' {
+			backslashes++
+		} else {
+			backslashes = 0
+		}
+
+		if c >= 0x10000 {
+			size += 2 // surrogate pair
+		} else {
+			size++
+		}
+	}
+
+	if quote {
+		size += 2 + backslashes // the trailing backslashes are escaped before the closing quote
+	}
+
+	return size
+}
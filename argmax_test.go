@@ -0,0 +1,115 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSplitCommand(test *testing.T) {
+	os.Setenv("ARG_MAX", "4096")
+	defer os.Unsetenv("ARG_MAX")
+
+	if n := ArgMax(); n != 4096 {
+		test.Fatalf("expected 4096 got %v", n)
+	}
+
+	items := make([]string, 100)
+	for i := range items {
+		items[i] = strings.Repeat("x", 20)
+	}
+
+	env := []string{"HOME=/home/user", "PATH=/bin:/usr/bin"}
+
+	batches, err := SplitCommand([]string{"rm", "-f"}, items, env)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	if len(batches) < 2 {
+		test.Errorf("expected multiple batches, got %v", len(batches))
+	}
+
+	count := 0
+	for _, batch := range batches {
+		if batch[0] != "rm" || batch[1] != "-f" {
+			test.Errorf("unexpected batch %q", batch)
+		}
+
+		size := 0
+		for _, arg := range append(batch, env...) {
+			size += len(arg) + 1 + 8
+		}
+
+		if size > 4096-ARG_MAX_HEADROOM {
+			test.Errorf("batch too large: %v bytes", size)
+		}
+
+		count += len(batch) - 2
+	}
+
+	if count != len(items) {
+		test.Errorf("expected %v items got %v", len(items), count)
+	}
+
+	if _, err := SplitCommand([]string{"rm"}, items, []string{strings.Repeat("x", 4096)}); !errors.Is(err, ErrArgTooLong) {
+		test.Errorf("expected ErrArgTooLong, got %v", err)
+	}
+}
+
+func TestSplitCommandWindows(test *testing.T) {
+	items := make([]string, 10000)
+	for i := range items {
+		items[i] = "x y" // quoted: "x y"
+	}
+
+	// the environment is not part of the command line
+	env := []string{"PATH=" + strings.Repeat("x", 40000)}
+
+	batches, err := splitCommand("windows", []string{"del"}, items, env)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// "del " is 4 characters, each item 6
+	if n := len(batches[0]) - 1; n != (32767-4)/6 {
+		test.Errorf("expected %v items got %v", (32767-4)/6, n)
+	}
+
+	for arg, expected := range map[string]int{
+		`a`:          2,
+		``:           3, // ""
+		`a b`:        6, // "a b"
+		`a"b`:        5, // a\"b
+		`a\b`:        4,
+		`a\"b`:       7, // a\\\"b
+		`a b\`:       8, // "a b\\"
+		"\U0001F600": 3,
+	} {
+		if size := windowsArgSize(arg); size != expected {
+			test.Errorf("%q: expected %v got %v", arg, expected, size)
+		}
+	}
+}
+
+func TestSplitCommandLinux(test *testing.T) {
+	if _, err := splitCommand("linux", []string{"rm"}, []string{strings.Repeat("x", MAX_ARG_STRLEN-1)}, nil); err != nil {
+		test.Errorf("unexpected error %v", err)
+	}
+
+	if _, err := splitCommand("linux", []string{"rm"}, []string{"a", strings.Repeat("x", MAX_ARG_STRLEN)}, nil); !errors.Is(err, ErrArgTooLong) {
+		test.Errorf("expected ErrArgTooLong, got %v", err)
+	}
+
+	if _, err := splitCommand("linux", []string{"rm"}, []string{"a"}, []string{strings.Repeat("x", MAX_ARG_STRLEN)}); !errors.Is(err, ErrArgTooLong) {
+		test.Errorf("expected ErrArgTooLong, got %v", err)
+	}
+
+	// no per-argument limit on other systems
+	if _, err := splitCommand("darwin", []string{"rm"}, []string{strings.Repeat("x", MAX_ARG_STRLEN)}, nil); err != nil {
+		test.Errorf("unexpected error %v", err)
+	}
+}
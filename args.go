@@ -1,20 +1,27 @@
 /*
- This package provides methods to parse a shell-like command line string into a list of arguments.
+This package provides methods to parse a shell-like command line string into a list of arguments.
 
- Words are split on white spaces, respecting quotes (single and double) and the escape character (backslash)
+Words are split on white spaces, respecting quotes (single and double) and the escape character (backslash)
+
+When building with TinyGo (i.e. for WASM targets) the tinygo build tag limits the dependencies to the
+small standard packages (strings, unicode, io, bufio, bytes, strconv, sort, errors): the integrations
+that depend on fmt, flag, os, time, net, regexp, log/slog, encoding/json and encoding/gob are excluded
+(NewFlags, ParseFlags, ArgMax, SplitCommand, Args.Attrs, Args.LogValue, EncodeTokens, DecodeTokens,
+CompletePath, PathCompletions, Format, JSONBrackets, RegexpRule, MustRegexpRule, ShellFormToExecForm,
+ExecFormToShellForm, Args.GetTimeOption, Args.GetIPOption, Args.GetCIDROption, Args.GetURLOption).
+Check with: go list -deps -tags tinygo
 */
 package args
 
 import (
 	"bufio"
 	"bytes"
-	"flag"
-	"fmt"
 	"io"
-	"io/ioutil"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 const (
@@ -24,56 +31,1012 @@ const (
 	SYMBOL_CHARS = `|><#{([`
 	NO_QUOTE     = unicode.ReplacementChar
 	RAW_QUOTE    = '`'
+	BOM          = '\uFEFF'
+	COMMENT_CHAR = '#'
 )
 
 var (
+	// OPERATORS is the default list of multi-character shell operators (see ShellOperators)
+	OPERATORS = []string{">>", "2>&1", "&&", "||", ";;"}
+
 	BRACKETS = map[rune]rune{
 		'{': '}',
 		'[': ']',
 		'(': ')',
 	}
+
+	SMART_QUOTES = map[rune]rune{
+		'\u201C': '\u201D', // “ ”
+		'\u2018': '\u2019', // ‘ ’
+	}
+)
+
+// InvalidUTF8Policy defines how the Scanner handles invalid UTF-8 sequences
+type InvalidUTF8Policy int
+
+const (
+	ReplaceInvalid InvalidUTF8Policy = iota // replace invalid bytes with U+FFFD (default)
+	PassInvalid                             // pass invalid bytes through untouched
+	RejectInvalid                           // return an InvalidUTF8Error
+)
+
+// QuotedNewlinePolicy defines how the Scanner handles an escaped newline inside double quotes
+type QuotedNewlinePolicy int
+
+const (
+	EscapeNewline  QuotedNewlinePolicy = iota // drop the escape character, keep the newline (default)
+	JoinNewline                               // drop both, joining the lines (POSIX shells)
+	LiteralNewline                            // keep both the escape character and the newline
+)
+
+// CommentPolicy defines if and where the Scanner recognizes comments
+// (from COMMENT_CHAR to the end of the line)
+type CommentPolicy int
+
+const (
+	NoComments        CommentPolicy = iota // no comment processing (default)
+	WordStartComments                      // a comment starts only at the beginning of a word, as in POSIX shells (file#1 is a word)
+	AnywhereComments                       // a comment can start anywhere outside quotes and brackets
+)
+
+// EndReason describes why a token ended
+type EndReason int
+
+const (
+	EndEOF       EndReason = iota // end of input
+	EndSpace                      // white space
+	EndQuote                      // closing quote
+	EndBracket                    // closing bracket
+	EndSymbol                     // the token is a symbol
+	EndUserToken                  // user defined token (see UserTokens)
+	EndComment                    // start of a comment
+	EndRule                       // the token matched a lexer rule (see Rules)
+)
+
+func (r EndReason) String() string {
+	switch r {
+	case EndEOF:
+		return "EOF"
+	case EndSpace:
+		return "space"
+	case EndQuote:
+		return "quote"
+	case EndBracket:
+		return "bracket"
+	case EndSymbol:
+		return "symbol"
+	case EndUserToken:
+		return "user token"
+	case EndComment:
+		return "comment"
+	case EndRule:
+		return "rule"
+	}
+
+	return "EndReason(" + strconv.Itoa(int(r)) + ")"
+}
+
+// TokenType is the type of a token
+type TokenType int
+
+const (
+	WordToken         TokenType = iota // a word (or quoted string, or bracketed expression)
+	SymbolToken                        // a single symbol character (see SymbolChars)
+	OperatorToken                      // a multi-character operator (see Operators)
+	KeywordToken                       // a shell reserved word in command position (see ShellKeywords)
+	CommentToken                       // a comment, without the comment character (see CommentTokens)
+	SubstitutionToken                  // a command or process substitution: $(...), <(...), >(...) (see SubstitutionCommand)
+
+	CustomToken TokenType = 100 // first value available for user defined token types (see Classify)
 )
 
+func (t TokenType) String() string {
+	switch t {
+	case WordToken:
+		return "word"
+	case SymbolToken:
+		return "symbol"
+	case OperatorToken:
+		return "operator"
+	case KeywordToken:
+		return "keyword"
+	case CommentToken:
+		return "comment"
+	case SubstitutionToken:
+		return "substitution"
+	}
+
+	if t >= CustomToken {
+		return "custom(" + strconv.Itoa(int(t-CustomToken)) + ")"
+	}
+
+	return "TokenType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Token is a token returned by Scanner.Next
+type Token struct {
+	Value  string
+	Type   TokenType
+	Delim  int       // the character that terminated the token (or the opening bracket for bracketed tokens)
+	End    EndReason // why the token ended
+	Quoted bool      // the token contains quotes: an empty quoted token ("" or '') is an empty argument
+	Line   int       // line number (starting from 1) of the beginning of the token
+
+	Segments []Segment // the segments composing the token (only if TrackSegments is set)
+}
+
+// Segment is a part of a token, with its quoting style
+type Segment struct {
+	Text  string // segment text, after quote and escape processing
+	Quote rune   // opening quote or bracket character (NO_QUOTE if unquoted)
+	Start int    // byte offset of the segment in the input (including quotes)
+	End   int    // byte offset of the end of the segment (including quotes)
+}
+
+// scannedRune is a rune read from the input
+type scannedRune struct {
+	c    rune
+	size int
+	raw  bool // invalid byte, read as is (see PassInvalid)
+	b    byte
+}
+
 type Scanner struct {
-	in              *bufio.Reader
+	in              io.RuneScanner
+	src             string         // the input, if scanning a string (see NewScannerString)
+	ascii           io.ByteScanner // the input, while it's pure ASCII (see readRune)
+	started         bool
+	offset          int           // byte offset of the next rune in the input
+	line            int           // number of newlines read
+	last            scannedRune   // last rune read
+	unread          []scannedRune // runes pushed back (stack)
+	buf             bytes.Buffer  // token buffer (reused)
 	InfieldBrackets bool
 	UserTokens      string
+	DropEmpty       bool // drop the unquoted empty tokens (i.e. before the user token in "a ,b")
+	UTF16           bool // detect (via BOM) and transcode UTF-16LE/BE input
+	InvalidUTF8     InvalidUTF8Policy
+	ASCIISpaces     bool   // only split words on ASCII space, tab and newline (POSIX)
+	SmartQuotes     bool   // recognize typographic quotes (“ ” ‘ ’) as quote pairs
+	QuoteChars      string // quote characters (empty means QUOTE_CHARS)
+	EscapeChar      rune   // escape character (0 means ESCAPE_CHAR)
+	NoEscape        bool   // disable escape processing
+	POSIXQuotes     bool   // no escape processing in single quotes, as in POSIX shells
+	QuotedNewline   QuotedNewlinePolicy
+	Comments        CommentPolicy
+	SymbolChars     string   // characters returned as single tokens (empty means SYMBOL_CHARS)
+	NoSymbols       bool     // disable symbol processing
+	Operators       []string // multi-character operators, recognized at the beginning of a word
+	Concat          bool     // concatenate adjacent quoted and unquoted segments into a single word
+	TrackSegments   bool     // return the list of segments composing each token (see Token.Segments)
+	MaxTokens       int      // maximum number of tokens returned by GetTokens (0 means no limit)
+	MaxTokenSize    int      // maximum size in bytes of a token, comment or remainder (0 means no limit)
+	Strict          bool     // return an error for any malformed input (see GetArgsStrict)
+	CEscapes        bool     // process C-style escape sequences (\n, \t, \xHH, \uHHHH, \nnn, ...)
+	QuotedCEscapes  bool     // process C-style escape sequences only inside double quotes
+	NoBrackets      bool     // disable bracket processing
+	MakeEscapes     bool     // Makefile recipe escapes: $$ is $, backslash-newline-tab joins lines
+	JSONBrackets    bool     // validate bracketed tokens ({...} and [...]) as JSON
+	Rules           []Rule   // additional lexer rules (see Rule)
+	AngleBrackets   bool     // recognize <...> as a bracket pair
+	DOSOptions      bool     // ParseArgs options are /flag, /flag:value or /flag=value
+	ShellKeywords   bool     // tag shell reserved words in command position as KeywordToken
+	CommentTokens   bool     // return comments as CommentToken tokens, instead of discarding them
+	EnvAssignments  bool     // ParseArgs returns leading NAME=value assignments in Args.Env
+	RawOptions      bool     // GetOptionTokens returns "--" as an option, instead of stopping there (old behavior)
+
+	EvalParens func(expr string) (string, error) // called with each (...) token, returns the replacement value
+	Classify   func(tok Token) []Token           // called with each token, returns the tokens to return instead
+	Trace      func(TraceEvent)                  // called on every state transition (see TraceEvent)
+
+	diagnostics []Diagnostic // problems found while scanning
+	strictErr   error        // first error found in strict mode
+	pending     []Token      // tokens returned by Classify, not consumed yet
+	restOffset  int          // offset of the remainder returned by getTokens
+	argPos      bool         // the last token was not in command position (see ShellKeywords)
+	state       State        // state at the end of the last token (see State)
 }
 
-// Creates a new Scanner with io.Reader as input source
+// Creates a new Scanner with io.Reader as input source.
+// If the reader already implements io.RuneScanner (i.e. strings.Reader, bufio.Reader)
+// it is used directly, without adding another level of buffering.
 func NewScanner(r io.Reader) *Scanner {
+	if rs, ok := r.(io.RuneScanner); ok {
+		return &Scanner{in: rs}
+	}
+
+	if rr, ok := r.(io.RuneReader); ok {
+		return NewScannerRunes(rr)
+	}
+
 	sc := Scanner{in: bufio.NewReader(r)}
 	return &sc
 }
 
+// Creates a new Scanner with io.RuneReader as input source
+func NewScannerRunes(r io.RuneReader) *Scanner {
+	if rs, ok := r.(io.RuneScanner); ok {
+		return &Scanner{in: rs}
+	}
+
+	sc := Scanner{in: &runeScanner{in: r}}
+	return &sc
+}
+
 // Creates a new Scanner with a string as input source
 func NewScannerString(s string) *Scanner {
-	sc := Scanner{in: bufio.NewReader(strings.NewReader(s))}
+	sc := Scanner{in: strings.NewReader(s), src: s}
 	return &sc
 }
 
+// runeScanner adds UnreadRune to an io.RuneReader
+type runeScanner struct {
+	in     io.RuneReader
+	last   rune
+	size   int
+	unread bool
+}
+
+func (rs *runeScanner) ReadRune() (r rune, size int, err error) {
+	if rs.unread {
+		rs.unread = false
+		return rs.last, rs.size, nil
+	}
+
+	r, size, err = rs.in.ReadRune()
+	if err == nil {
+		rs.last, rs.size = r, size
+	} else {
+		rs.size = -1
+	}
+	return
+}
+
+func (rs *runeScanner) UnreadRune() error {
+	if rs.unread || rs.size <= 0 {
+		return bufio.ErrInvalidUnreadRune
+	}
+
+	rs.unread = true
+	return nil
+}
+
+// utf16Reader decodes UTF-16 input (with the specified byte order) into runes
+type utf16Reader struct {
+	in        io.ByteReader
+	bigEndian bool
+}
+
+func (ur *utf16Reader) readUnit() (uint16, error) {
+	b1, err := ur.in.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	b2, err := ur.in.ReadByte()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if ur.bigEndian {
+		return uint16(b1)<<8 | uint16(b2), nil
+	}
+
+	return uint16(b2)<<8 | uint16(b1), nil
+}
+
+func (ur *utf16Reader) ReadRune() (r rune, size int, err error) {
+	u1, err := ur.readUnit()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !utf16.IsSurrogate(rune(u1)) {
+		return rune(u1), 2, nil
+	}
+
+	u2, err := ur.readUnit()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return utf16.DecodeRune(rune(u1), rune(u2)), 4, nil
+}
+
+// start is called before reading the first rune: it checks for a BOM
+// and, if enabled, switches to UTF-16 decoding.
+func (scanner *Scanner) start() {
+	scanner.started = true
+
+	if scanner.UTF16 {
+		if r, ok := scanner.in.(io.Reader); ok {
+			br := bufio.NewReader(r)
+
+			if bom, err := br.Peek(2); err == nil {
+				switch {
+				case bom[0] == 0xFF && bom[1] == 0xFE:
+					br.Discard(2)
+					scanner.in = &runeScanner{in: &utf16Reader{in: br}}
+					return
+
+				case bom[0] == 0xFE && bom[1] == 0xFF:
+					br.Discard(2)
+					scanner.in = &runeScanner{in: &utf16Reader{in: br, bigEndian: true}}
+					return
+				}
+			}
+
+			scanner.in = br
+		}
+	}
+
+	//
+	// skip UTF-8 BOM
+	//
+	if c, size, err := scanner.in.ReadRune(); err == nil {
+		if c == BOM {
+			scanner.offset += size
+		} else {
+			scanner.in.UnreadRune()
+		}
+	}
+
+	if bs, ok := scanner.in.(io.ByteScanner); ok {
+		scanner.ascii = bs
+	}
+}
+
+// readRune returns the next rune from the input
+func (scanner *Scanner) readRune() (rune, int, error) {
+	if !scanner.started {
+		scanner.start()
+	}
+
+	if n := len(scanner.unread); n > 0 {
+		r := scanner.unread[n-1]
+		scanner.unread = scanner.unread[:n-1]
+		scanner.last = r
+		scanner.offset += r.size
+		if r.c == '\n' {
+			scanner.line++
+		}
+		return r.c, r.size, nil
+	}
+
+	scanner.last = scannedRune{}
+
+	if scanner.ascii != nil {
+		//
+		// fast path: read bytes, until the first non-ASCII one
+		//
+		b, err := scanner.ascii.ReadByte()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if b < utf8.RuneSelf && !(b == '$' && scanner.MakeEscapes) {
+			c := rune(b)
+			scanner.last = scannedRune{c: c, size: 1}
+			scanner.offset++
+			if c == '\n' {
+				scanner.line++
+			}
+			return c, 1, nil
+		}
+
+		scanner.ascii.UnreadByte()
+		if b >= utf8.RuneSelf {
+			scanner.ascii = nil
+		}
+	}
+
+	c, size, err := scanner.in.ReadRune()
+	if err != nil {
+		return c, size, err
+	}
+
+	r := scannedRune{c: c, size: size}
+
+	if c == utf8.RuneError && size == 1 {
+		policy := scanner.InvalidUTF8
+		if scanner.Strict && policy == ReplaceInvalid {
+			policy = RejectInvalid
+		}
+
+		switch policy {
+		case RejectInvalid:
+			err = &InvalidUTF8Error{Offset: scanner.offset}
+			scanner.offset += size
+			return c, 0, err
+
+		case PassInvalid:
+			if bs, ok := scanner.in.(io.ByteScanner); ok && scanner.in.UnreadRune() == nil {
+				if b, err := bs.ReadByte(); err == nil {
+					r.raw = true
+					r.b = b
+				}
+			}
+		}
+	}
+
+	scanner.last = r
+	scanner.offset += size
+	if c == '\n' {
+		scanner.line++
+	}
+
+	if c == '$' && scanner.MakeEscapes {
+		//
+		// $$ is an escaped $
+		//
+		if n, nsize, err := scanner.in.ReadRune(); err == nil {
+			if n == '$' {
+				scanner.last.size += nsize
+				scanner.offset += nsize
+				return c, size + nsize, nil
+			}
+
+			scanner.in.UnreadRune()
+		}
+	}
+
+	return c, size, nil
+}
+
+// unreadRune pushes back the last rune read
+func (scanner *Scanner) unreadRune() error {
+	if scanner.last.size == 0 {
+		return bufio.ErrInvalidUnreadRune
+	}
+
+	scanner.pushBack(scanner.last)
+	scanner.last = scannedRune{}
+	return nil
+}
+
+// pushBack pushes back a list of runes, so that they are read again in the same order
+func (scanner *Scanner) pushBack(runes ...scannedRune) {
+	for i := len(runes) - 1; i >= 0; i-- {
+		scanner.unread = append(scanner.unread, runes[i])
+		scanner.offset -= runes[i].size
+		if runes[i].c == '\n' {
+			scanner.line--
+		}
+	}
+}
+
+// writeRune appends the last rune read to the buffer
+// (or the original byte, if it was invalid and the policy is PassInvalid)
+func (scanner *Scanner) writeRune(buf *bytes.Buffer, c rune) {
+	if scanner.last.raw && c == utf8.RuneError {
+		buf.WriteByte(scanner.last.b)
+	} else {
+		buf.WriteRune(c)
+	}
+}
+
+// isSpace returns true if c is a word separator
+func (scanner *Scanner) isSpace(c rune) bool {
+	if scanner.ASCIISpaces {
+		return c == ' ' || c == '\t' || c == '\n'
+	}
+
+	return unicode.IsSpace(c)
+}
+
+// isSymbol returns true if c is a symbol character
+func (scanner *Scanner) isSymbol(c rune) bool {
+	if scanner.NoSymbols {
+		return false
+	}
+
+	if scanner.SymbolChars != "" {
+		return strings.ContainsRune(scanner.SymbolChars, c)
+	}
+
+	return strings.ContainsRune(SYMBOL_CHARS, c)
+}
+
+// closeBracket returns the closing bracket for c, if c is an opening bracket
+func (scanner *Scanner) closeBracket(c rune) (rune, bool) {
+	if scanner.NoBrackets {
+		return 0, false
+	}
+
+	if c == '<' && scanner.AngleBrackets {
+		//
+		// only if not followed by a space or another redirection character (<<, <>, <&)
+		//
+		if n, _, err := scanner.readRune(); err == nil {
+			scanner.unreadRune()
+
+			if !scanner.isSpace(n) && !strings.ContainsRune("<>&", n) {
+				return '>', true
+			}
+		}
+
+		return 0, false
+	}
+
+	b, ok := BRACKETS[c]
+	return b, ok
+}
+
+// isCloseBracket returns true if c is a closing bracket
+func (scanner *Scanner) isCloseBracket(c rune) bool {
+	if c == '>' && scanner.AngleBrackets {
+		return true
+	}
+
+	for _, b := range BRACKETS {
+		if b == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchOperator returns the longest operator starting with c (consuming it) or an empty string
+func (scanner *Scanner) matchOperator(c rune) string {
+	if len(scanner.Operators) == 0 {
+		return ""
+	}
+
+	isPrefix := func(s string) bool {
+		for _, op := range scanner.Operators {
+			if strings.HasPrefix(op, s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	isOperator := func(s string) bool {
+		for _, op := range scanner.Operators {
+			if op == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	first := scanner.last
+	prefix := string(c)
+	match := ""
+	matchLen := 0
+	read := []scannedRune{}
+
+	for isPrefix(prefix) {
+		if len(read) > 0 && isOperator(prefix) {
+			match = prefix
+			matchLen = len(read)
+		}
+
+		r, _, err := scanner.readRune()
+		if err != nil {
+			break
+		}
+
+		read = append(read, scanner.last)
+		prefix += string(r)
+	}
+
+	scanner.pushBack(read[matchLen:]...)
+
+	if match == "" {
+		scanner.last = first
+	} else if matchLen > 0 {
+		scanner.last = read[matchLen-1]
+	}
+	return match
+}
+
+// escapeChar returns the escape character in use
+func (scanner *Scanner) escapeChar() rune {
+	if scanner.EscapeChar != 0 {
+		return scanner.EscapeChar
+	}
+
+	return ESCAPE_CHAR
+}
+
+// isEscape returns true if c is the escape character
+func (scanner *Scanner) isEscape(c rune) bool {
+	return !scanner.NoEscape && c == scanner.escapeChar()
+}
+
+// isQuote returns true if c starts a quoted string
+func (scanner *Scanner) isQuote(c rune) bool {
+	quotes := scanner.QuoteChars
+	if quotes == "" {
+		quotes = QUOTE_CHARS
+	}
+
+	if strings.ContainsRune(quotes, c) {
+		return true
+	}
+
+	if scanner.SmartQuotes {
+		_, ok := SMART_QUOTES[c]
+		return ok
+	}
+
+	return false
+}
+
+// isRawQuote returns true if c starts a quoted string where escapes are not processed
+func (scanner *Scanner) isRawQuote(c rune) bool {
+	return c == RAW_QUOTE || (scanner.POSIXQuotes && c == '\'')
+}
+
+// closeQuote returns the character that closes a quoted string started with c
+func (scanner *Scanner) closeQuote(c rune) rune {
+	if scanner.SmartQuotes {
+		if q, ok := SMART_QUOTES[c]; ok {
+			return q
+		}
+	}
+
+	return c
+}
+
+// readComment returns the text of a comment, up to the end of the line (the newline is consumed but not returned)
+func (scanner *Scanner) readComment() (string, error) {
+	var buf bytes.Buffer
+
+	for {
+		if scanner.tooLarge(buf.Len()) {
+			return buf.String(), &ParseError{Offset: scanner.offset, Err: ErrTokenTooLarge}
+		}
+
+		c, _, err := scanner.readRune()
+		if err != nil {
+			return buf.String(), err
+		}
+
+		if c == '\n' {
+			return buf.String(), nil
+		}
+
+		scanner.writeRune(&buf, c)
+	}
+}
+
+// skipComment skips the input up to (and including) the next newline
+func (scanner *Scanner) skipComment() error {
+	for {
+		c, _, err := scanner.readRune()
+		if err != nil {
+			return err
+		}
+
+		if c == '\n' {
+			return nil
+		}
+	}
+}
+
+// writeCEscape appends to the buffer the character for the C-style escape sequence starting with c
+// (the character following the escape character). Unknown sequences are written as c.
+func (scanner *Scanner) writeCEscape(buf *bytes.Buffer, c rune) {
+	// readDigits reads up to n digits in the specified base
+	readDigits := func(n, base int) (v int, read int) {
+		for ; read < n; read++ {
+			d, _, err := scanner.readRune()
+			if err != nil {
+				break
+			}
+
+			digit := strings.IndexRune("0123456789abcdef"[:base], unicode.ToLower(d))
+			if digit < 0 {
+				scanner.unreadRune()
+				break
+			}
+
+			v = v*base + digit
+		}
+
+		return
+	}
+
+	switch c {
+	case 'a':
+		buf.WriteByte('\a')
+	case 'b':
+		buf.WriteByte('\b')
+	case 'f':
+		buf.WriteByte('\f')
+	case 'n':
+		buf.WriteByte('\n')
+	case 'r':
+		buf.WriteByte('\r')
+	case 't':
+		buf.WriteByte('\t')
+	case 'v':
+		buf.WriteByte('\v')
+	case 's':
+		buf.WriteByte(' ')
+
+	case 'x':
+		if v, n := readDigits(2, 16); n > 0 {
+			buf.WriteByte(byte(v))
+		} else {
+			buf.WriteRune(c)
+		}
+
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+
+		if v, n := readDigits(size, 16); n > 0 {
+			buf.WriteRune(rune(v))
+		} else {
+			buf.WriteRune(c)
+		}
+
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		v, n := readDigits(2, 8)
+		buf.WriteByte(byte(int(c-'0')<<(3*n) | v))
+
+	default:
+		scanner.writeRune(buf, c)
+	}
+}
+
+// readAll returns the remaining input as a string
+func (scanner *Scanner) readAll() (string, error) {
+	var buf bytes.Buffer
+
+	for {
+		if scanner.tooLarge(buf.Len()) {
+			return buf.String(), &ParseError{Offset: scanner.offset, Err: ErrTokenTooLarge}
+		}
+
+		c, _, err := scanner.readRune()
+		if err == io.EOF {
+			return buf.String(), nil
+		}
+		if err != nil {
+			return buf.String(), err
+		}
+
+		scanner.writeRune(&buf, c)
+	}
+}
+
+// readSubstitution reads the rest of a command substitution, after the open parenthesis, up to the matching
+// close parenthesis (respecting nested parentheses, quotes and escapes) and appends it to the buffer.
+// An unterminated substitution is reported (see ErrUnbalancedBracket) and returned up to the end of the input.
+func (scanner *Scanner) readSubstitution(buf *bytes.Buffer, start int) error {
+	depth := 1
+	quote := NO_QUOTE
+	escape := false
+
+	for {
+		c, _, err := scanner.readRune()
+		if err == io.EOF {
+			scanner.report(SeverityError, &ParseError{Offset: start, Err: ErrUnbalancedBracket})
+			return scanner.strictError()
+		}
+		if err != nil {
+			return err
+		}
+
+		scanner.writeRune(buf, c)
+
+		switch {
+		case escape:
+			escape = false
+		case quote == '\'':
+			if c == '\'' {
+				quote = NO_QUOTE
+			}
+		case c == ESCAPE_CHAR:
+			escape = true
+		case quote == '"':
+			if c == '"' {
+				quote = NO_QUOTE
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth--; depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// tooLarge returns true if size exceeds MaxTokenSize
+func (scanner *Scanner) tooLarge(size int) bool {
+	return scanner.MaxTokenSize > 0 && size > scanner.MaxTokenSize
+}
+
+// SkipLine discards the input up to and including the next newline that is not quoted or escaped,
+// so that a parser can recover from a malformed command and continue with the next line.
+// Returns io.EOF if the end of the input is reached first.
+func (scanner *Scanner) SkipLine() error {
+	scanner.pending = nil
+
+	quote := NO_QUOTE
+	rawq := false
+	escape := false
+
+	for {
+		c, _, err := scanner.readRune()
+		if err == io.EOF {
+			return err
+		}
+		if err != nil {
+			if _, ok := err.(*InvalidUTF8Error); ok {
+				continue
+			}
+			return err
+		}
+
+		switch {
+		case escape:
+			escape = false
+
+		case scanner.isEscape(c) && !rawq:
+			escape = true
+
+		case quote != NO_QUOTE:
+			if c == quote {
+				quote = NO_QUOTE
+				rawq = false
+			}
+
+		case scanner.isQuote(c):
+			quote = scanner.closeQuote(c)
+			rawq = scanner.isRawQuote(c)
+
+		case c == '\n':
+			return nil
+		}
+	}
+}
+
 // Get the next token from the Scanner, return io.EOF when done
 func (scanner *Scanner) NextToken() (s string, delim int, err error) {
-	buf := bytes.NewBufferString("")
+	tok, err := scanner.Next()
+	return tok.Value, tok.Delim, err
+}
+
+// Get the next token from the Scanner, including the reason why the token ended.
+// Returns io.EOF when done
+func (scanner *Scanner) Next() (tok Token, err error) {
+	for {
+		if n := len(scanner.pending); n > 0 {
+			tok = scanner.pending[0]
+			scanner.pending = scanner.pending[1:]
+			return tok, nil
+		}
+
+		tok, err = scanner.next()
+		if err == nil && scanner.ShellKeywords {
+			scanner.tagKeyword(&tok)
+		}
+		if err != nil || scanner.Classify == nil {
+			return
+		}
+
+		scanner.pending = scanner.Classify(tok)
+	}
+}
+
+// next returns the next token from the input
+func (scanner *Scanner) next() (tok Token, err error) {
+	buf := &scanner.buf
+	buf.Reset()
 	first := true
 	escape := false
 	rawq := false
 	infield := false
 	quote := NO_QUOTE    // invalid character - not a quote
 	brackets := []rune{} // stack of open brackets
+	quotePos := 0        // offset of the open quote
+	bracketsPos := []int{}
+	escapePos := 0
+
+	var seg *Segment // current segment, if TrackSegments
+	segText := 0     // start of the segment text in buf
+	pos := 0         // offset of the current rune
+	start := -1      // offset of the first rune of the token
+	subst := -1      // length of the token, if it's only a substitution (see readSubstitution)
+
+	// value returns the token value: a slice of the input string if the token text is unchanged
+	// (no quotes or escapes), to avoid a copy
+	value := func() string {
+		if scanner.src != "" && !scanner.UTF16 && start >= 0 {
+			if end := start + buf.Len(); end <= len(scanner.src) && scanner.src[start:end] == string(buf.Bytes()) {
+				return scanner.src[start:end]
+			}
+		}
+
+		return buf.String()
+	}
+
+	openSeg := func(quote rune) {
+		if scanner.TrackSegments && seg == nil {
+			seg = &Segment{Quote: quote, Start: pos}
+			segText = buf.Len()
+		}
+	}
+
+	closeSeg := func(end int) {
+		if seg != nil {
+			seg.Text = buf.String()[segText:]
+			seg.End = end
+			tok.Segments = append(tok.Segments, *seg)
+			seg = nil
+		}
+	}
+
+	defer func() {
+		if subst >= 0 && err == nil && len(tok.Value) == subst && !tok.Quoted {
+			tok.Type = SubstitutionToken
+		}
+
+		if start >= 0 { // keep the state of the last token at EOF
+			scanner.state = State{Quote: quote, Escape: escape}
+			if len(brackets) > 0 {
+				scanner.state.Brackets = append([]rune(nil), brackets...)
+			}
+		}
+
+		if err == nil && scanner.Trace != nil {
+			scanner.Trace(TraceEvent{Kind: TraceToken, Offset: scanner.offset, Rune: rune(tok.Delim), Value: tok.Value})
+		}
+
+		if seg != nil && err == nil {
+			switch tok.End {
+			case EndSpace, EndUserToken, EndComment:
+				closeSeg(pos)
+			default:
+				closeSeg(scanner.offset)
+			}
+		}
+	}()
 
 	for {
-		if c, _, e := scanner.in.ReadRune(); e == nil {
+		if scanner.tooLarge(buf.Len()) {
+			err = &ParseError{Offset: scanner.offset, Err: ErrTokenTooLarge}
+			return // ("", error)
+		}
+
+		if c, size, e := scanner.readRune(); e == nil {
+			pos = scanner.offset - size
+			scanner.trace(TraceRune, pos, c, len(brackets))
+
+			if start < 0 && !scanner.isSpace(c) {
+				start = pos
+			}
+
+			if first {
+				tok.Line = scanner.line + 1
+			}
+
 			//
 			// check escape character
 			//
-			if c == ESCAPE_CHAR && !escape && !rawq {
+			if scanner.isEscape(c) && !escape && !rawq {
 				escape = true
+				escapePos = pos
 				first = false
+				openSeg(NO_QUOTE)
 
-                                if infield {
-				    buf.WriteString(string(c))
-                                }
+				if infield {
+					scanner.writeRune(buf, c)
+				}
 				continue
 			}
 
@@ -82,7 +1045,33 @@ func (scanner *Scanner) NextToken() (s string, delim int, err error) {
 			//
 			if escape {
 				escape = false
-				buf.WriteString(string(c))
+
+				if c == '\n' && scanner.MakeEscapes {
+					//
+					// recipe line continuation: join the lines, removing the leading tab
+					//
+					if t, _, err := scanner.readRune(); err == nil && t != '\t' {
+						scanner.unreadRune()
+					}
+					continue
+				}
+
+				if c == '\n' && quote == '"' && !infield {
+					switch scanner.QuotedNewline {
+					case JoinNewline:
+						continue
+
+					case LiteralNewline:
+						scanner.writeRune(buf, scanner.escapeChar())
+					}
+				}
+
+				if (scanner.CEscapes || scanner.QuotedCEscapes && quote == '"') && !infield {
+					scanner.writeCEscape(buf, c)
+					continue
+				}
+
+				scanner.writeRune(buf, c)
 				continue
 			}
 
@@ -90,42 +1079,144 @@ func (scanner *Scanner) NextToken() (s string, delim int, err error) {
 			// checks for beginning of token
 			//
 			if first {
-				if unicode.IsSpace(c) {
+				if scanner.isSpace(c) {
 					//
 					// skip leading spaces
 					//
 					continue
 				}
 
+				if c == COMMENT_CHAR && scanner.Comments != NoComments && scanner.CommentTokens {
+					//
+					// return the comment as a token
+					//
+					openSeg(NO_QUOTE)
+					text, e := scanner.readComment()
+					if e != nil && e != io.EOF {
+						err = e
+						return // ("", error)
+					}
+
+					end := scanner.offset
+					if e == nil {
+						end-- // newline
+						tok.Delim = '\n'
+					}
+
+					buf.WriteString(text)
+					closeSeg(end)
+					tok.Value = text
+					tok.Type = CommentToken
+					tok.End = EndComment
+					return // (comment, nil)
+				}
+
+				if c == COMMENT_CHAR && scanner.Comments != NoComments {
+					//
+					// skip comment
+					//
+					if e := scanner.skipComment(); e != nil {
+						err = e
+						return // ("", io.EOF)
+					}
+					scanner.argPos = false // the comment ended with a newline (see ShellKeywords)
+					continue
+				}
+
+				if len(scanner.Rules) > 0 && !scanner.isQuote(c) {
+					//
+					// if it matches a lexer rule, return it as a token
+					//
+					value, typ, ok, e := scanner.matchRule()
+					if e != nil {
+						err = e
+						return // ("", error)
+					}
+
+					if ok {
+						openSeg(NO_QUOTE)
+						buf.WriteString(value)
+						tok.Value = value
+						tok.Type = typ
+						tok.End = EndRule
+						return // (token, nil)
+					}
+				}
+
 				first = false
 
-				if strings.ContainsRune(QUOTE_CHARS, c) {
+				if scanner.isQuote(c) {
 					//
 					// start quoted token
 					//
-					quote = c
-					rawq = c == RAW_QUOTE
+					quote = scanner.closeQuote(c)
+					quotePos = pos
+					scanner.trace(TraceQuoteOpen, pos, c, len(brackets))
+					rawq = scanner.isRawQuote(c)
+					tok.Quoted = true
+					openSeg(c)
 					continue
 				}
 
-				if b, ok := BRACKETS[c]; ok {
+				if c == '<' || c == '>' {
+					//
+					// process substitution: <(...) or >(...) is kept as is, up to the matching parenthesis
+					//
+					if n, _, e := scanner.readRune(); e == nil {
+						if n == '(' {
+							openSeg(NO_QUOTE)
+							scanner.writeRune(buf, c)
+							buf.WriteRune(n)
+
+							if e := scanner.readSubstitution(buf, pos); e != nil {
+								err = e
+								return // ("", error)
+							}
+
+							subst = buf.Len()
+							continue
+						}
+
+						scanner.unreadRune()
+					}
+				}
+
+				if b, ok := scanner.closeBracket(c); ok {
 					//
 					// start a bracketed session
 					//
-					delim = int(c)
+					openSeg(c)
+					tok.Delim = int(c)
 					brackets = append(brackets, b)
-					buf.WriteString(string(c))
+					bracketsPos = append(bracketsPos, pos)
+					scanner.trace(TraceBracketPush, pos, c, len(brackets))
+					scanner.writeRune(buf, c)
 					continue
 				}
 
-				if strings.ContainsRune(SYMBOL_CHARS, c) {
+				if op := scanner.matchOperator(c); op != "" {
 					//
-					// if it's a symbol, return  all the remaining characters
+					// if it's an operator, return it as a token
 					//
-					buf.WriteString(string(c))
-					_, err = io.Copy(buf, scanner.in)
-					s = buf.String()
-					return // (token, delim, err)
+					openSeg(NO_QUOTE)
+					buf.WriteString(op)
+					tok.Value = value()
+					tok.Type = OperatorToken
+					tok.End = EndSymbol
+					return // (operator, nil)
+				}
+
+				if scanner.isSymbol(c) {
+					//
+					// if it's a symbol, return it as a token
+					//
+					openSeg(NO_QUOTE)
+					scanner.writeRune(buf, c)
+					tok.Value = value()
+					tok.Type = SymbolToken
+					tok.Delim = int(c)
+					tok.End = EndSymbol
+					return // (symbol, nil)
 				}
 			}
 
@@ -133,42 +1224,113 @@ func (scanner *Scanner) NextToken() (s string, delim int, err error) {
 				//
 				// terminate on spaces
 				//
-				if unicode.IsSpace(c) && quote == NO_QUOTE {
-					s = buf.String()
-					delim = int(c)
-					return // (token, delim, nil)
+				if scanner.isSpace(c) && quote == NO_QUOTE {
+					tok.Value = value()
+					tok.Delim = int(c)
+					tok.End = EndSpace
+					return // (token, nil)
 				}
 
 				//
 				// close quote and terminate
 				//
-				if c == quote {
+				if quote != NO_QUOTE && c == quote {
 					quote = NO_QUOTE
 					rawq = false
+					scanner.trace(TraceQuoteClose, pos, c, len(brackets))
+
+					if scanner.Concat && !infield {
+						//
+						// continue with the next segment
+						//
+						closeSeg(scanner.offset)
+						continue
+					}
+
 					if infield {
-						buf.WriteString(string(c))
+						scanner.writeRune(buf, c)
+					}
+					tok.Value = value()
+					tok.Delim = int(c)
+					tok.End = EndQuote
+					return // (token, nil)
+				}
+
+				if quote == NO_QUOTE && c == COMMENT_CHAR && scanner.Comments == AnywhereComments {
+					//
+					// comment in the middle of a word
+					//
+					if scanner.CommentTokens {
+						scanner.unreadRune() // return the comment as the next token
+					} else {
+						scanner.skipComment()
 					}
-					s = buf.String()
-					delim = int(c)
-					return // (token, delim, nil)
+					tok.Value = value()
+					tok.Delim = int(c)
+					tok.End = EndComment
+					return // (token, nil)
+				}
+
+				if scanner.Concat && !scanner.InfieldBrackets && quote == NO_QUOTE && scanner.isQuote(c) {
+					//
+					// start quoted segment
+					//
+					closeSeg(pos)
+					quote = scanner.closeQuote(c)
+					quotePos = pos
+					scanner.trace(TraceQuoteOpen, pos, c, len(brackets))
+					rawq = scanner.isRawQuote(c)
+					tok.Quoted = true
+					openSeg(c)
+					continue
 				}
 
 				if scanner.InfieldBrackets {
-					if b, ok := BRACKETS[c]; ok {
+					if b, ok := scanner.closeBracket(c); ok {
 						//
 						// start a bracketed session
 						//
 						brackets = append(brackets, b)
+						bracketsPos = append(bracketsPos, pos)
+						scanner.trace(TraceBracketPush, pos, c, len(brackets))
 						infield = true
 					}
 
-					if quote == NO_QUOTE && strings.ContainsRune(QUOTE_CHARS, c) {
+					if quote == NO_QUOTE && scanner.isQuote(c) {
 						//
 						// start quoted token
 						//
-						quote = c
-						rawq = c == RAW_QUOTE
+						quote = scanner.closeQuote(c)
+						quotePos = pos
+						scanner.trace(TraceQuoteOpen, pos, c, len(brackets))
+						rawq = scanner.isRawQuote(c)
 						infield = true
+						tok.Quoted = true
+					}
+				}
+
+				if quote == NO_QUOTE && c == '$' && scanner.last.size == 1 {
+					//
+					// command substitution: $(...) is kept as is, up to the matching parenthesis
+					//
+					if n, _, e := scanner.readRune(); e == nil {
+						if n == '(' {
+							openSeg(NO_QUOTE)
+							empty := buf.Len() == 0
+							buf.WriteString("$(")
+
+							if e := scanner.readSubstitution(buf, pos); e != nil {
+								err = e
+								return // ("", error)
+							}
+
+							if empty {
+								subst = buf.Len()
+							}
+							continue
+						}
+
+						scanner.unreadRune()
 					}
 				}
 
@@ -176,58 +1338,101 @@ func (scanner *Scanner) NextToken() (s string, delim int, err error) {
 					//
 					// user defined token
 					//
-					s = buf.String()
-					delim = int(c)
-					return
+					tok.Value = value()
+					tok.Delim = int(c)
+					tok.End = EndUserToken
+					return // (token, nil)
 				}
 
 				//
 				// append to buffer
 				//
-				buf.WriteString(string(c))
+				openSeg(NO_QUOTE)
+				scanner.writeRune(buf, c)
 			} else {
 				//
 				// append to buffer
 				//
-				buf.WriteString(string(c))
+				scanner.writeRune(buf, c)
 
 				last := len(brackets) - 1
 
 				if quote == NO_QUOTE {
 					if c == brackets[last] {
+						start := bracketsPos[0]
 						brackets = brackets[:last] // pop
+						bracketsPos = bracketsPos[:last]
+						scanner.trace(TraceBracketPop, pos, c, len(brackets))
 
 						if len(brackets) == 0 {
-							s = buf.String()
-							return // (token, delim, nil)
+							tok.Value = value()
+							tok.End = EndBracket
+
+							if scanner.JSONBrackets && !infield && (tok.Delim == '{' || tok.Delim == '[') {
+								err = scanner.validateJSON(tok.Value, start)
+							}
+							if scanner.EvalParens != nil && !infield && tok.Delim == '(' && err == nil {
+								if tok.Value, err = scanner.EvalParens(tok.Value); err != nil {
+									err = &ParseError{Offset: start, Err: err}
+								}
+							}
+							return // (token, nil)
 						}
-					} else if strings.ContainsRune(QUOTE_CHARS, c) {
+					} else if scanner.isQuote(c) {
 						//
 						// start quoted token
 						//
-						quote = c
-						rawq = c == RAW_QUOTE
-					} else if b, ok := BRACKETS[c]; ok {
+						quote = scanner.closeQuote(c)
+						quotePos = pos
+						scanner.trace(TraceQuoteOpen, pos, c, len(brackets))
+						rawq = scanner.isRawQuote(c)
+					} else if b, ok := scanner.closeBracket(c); ok {
 						brackets = append(brackets, b)
+						bracketsPos = append(bracketsPos, pos)
+						scanner.trace(TraceBracketPush, pos, c, len(brackets))
+					} else if scanner.isCloseBracket(c) {
+						scanner.report(SeverityError, &ParseError{Offset: pos, Err: ErrMismatchedBracket,
+							Detail: strconv.QuoteRune(c) + " (expected " + strconv.QuoteRune(brackets[last]) + ")"})
+
+						if err = scanner.strictError(); err != nil {
+							return // ("", error)
+						}
 					}
 				} else if c == quote {
 					quote = NO_QUOTE
 					rawq = false
+					scanner.trace(TraceQuoteClose, pos, c, len(brackets))
 				}
 			}
 		} else {
 			if e == io.EOF {
-				if buf.Len() > 0 {
-					s = buf.String()
-					return // (token, 0, nil)
+				if escape {
+					scanner.report(SeverityError, &ParseError{Offset: escapePos, Err: ErrTrailingEscape})
+				}
+				if quote != NO_QUOTE {
+					scanner.report(SeverityError, &ParseError{Offset: quotePos, Err: ErrUnterminatedQuote})
+				}
+				for _, p := range bracketsPos {
+					scanner.report(SeverityError, &ParseError{Offset: p, Err: ErrUnbalancedBracket})
+				}
+
+				if err = scanner.strictError(); err != nil {
+					return // ("", error)
+				}
+
+				if buf.Len() > 0 || (tok.Quoted && quote == NO_QUOTE) {
+					tok.Value = value()
+					tok.End = EndEOF
+					return // (token, nil)
 				}
 			}
+			if e != io.EOF {
+				tok.Value = value() // partial token
+			}
 			err = e
-			return // ("", 0, io.EOF)
+			return // ("", io.EOF)
 		}
 	}
-
-	return
 }
 
 // Return all tokens as an array of strings
@@ -236,24 +1441,68 @@ func (scanner *Scanner) GetTokens() (tokens []string, err error) {
 	return
 }
 
+// Tokens returns all the remaining tokens (with their type, position and segments, if TrackSegments is set)
+func (scanner *Scanner) Tokens() ([]Token, error) {
+	tokens := []Token{}
+
+	for {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			return tokens, nil
+		}
+		if err != nil {
+			return tokens, err
+		}
+
+		tokens = append(tokens, tok)
+	}
+}
+
 func (scanner *Scanner) GetTokensN(n int) ([]string, string, error) {
 	return scanner.getTokens(n)
 }
 
-// Return all "option" tokens (tokens that start with "-") and remainder of the line
+// Return all "option" tokens (unquoted tokens that start with "-") and remainder of the line.
+// Option parsing stops at the first token that is not an option (including quoted tokens, i.e. "-x")
+// or at "--", that is not returned (unless RawOptions is set).
 func (scanner *Scanner) GetOptionTokens() ([]string, string, error) {
 	return scanner.getTokens(-1)
 }
 
+// RestOffset returns the byte offset in the input of the remainder returned by the last call to GetTokensN
+// or GetOptionTokens (-1 if there was no remainder), so that the caller can access the original text
+func (scanner *Scanner) RestOffset() int {
+	return scanner.restOffset
+}
+
+// keepToken returns false for an unquoted empty token that ends at a user token, if DropEmpty is set
+func (scanner *Scanner) keepToken(tok Token) bool {
+	return !scanner.DropEmpty || tok.Value != "" || tok.Quoted || tok.End != EndUserToken
+}
+
+// appendToken appends the token value (and the user token that terminated it, if any) to the list of tokens
+func (scanner *Scanner) appendToken(tokens []string, tok Token) []string {
+	if scanner.keepToken(tok) {
+		tokens = append(tokens, tok.Value)
+	}
+
+	if tok.End == EndUserToken {
+		tokens = append(tokens, string(rune(tok.Delim)))
+	}
+
+	return tokens
+}
+
 func (scanner *Scanner) getTokens(max int) ([]string, string, error) {
 	tokens := []string{}
+	scanner.restOffset = -1
 
 	options := max < 0
 
 	for i := 0; max <= 0 || i < max; i++ {
 		if options {
 			for {
-				c, _, err := scanner.in.ReadRune()
+				c, _, err := scanner.readRune()
 				if err == io.EOF {
 					return tokens, "", nil
 				}
@@ -262,43 +1511,58 @@ func (scanner *Scanner) getTokens(max int) ([]string, string, error) {
 				}
 
 				if c == OPTION_CHAR {
-					scanner.in.UnreadRune()
+					scanner.unreadRune()
 					break
 				}
 
-				if !unicode.IsSpace(c) {
-					scanner.in.UnreadRune()
-					rest, err := ioutil.ReadAll(scanner.in)
-					return tokens, string(rest), err
+				if !scanner.isSpace(c) {
+					scanner.unreadRune()
+					scanner.restOffset = scanner.offset
+					rest, err := scanner.readAll()
+					return tokens, rest, err
 				}
 
 				// skipping spaces until next token
 			}
 		}
 
-		tok, delim, err := scanner.NextToken()
+		if scanner.MaxTokens > 0 && len(tokens) >= scanner.MaxTokens {
+			return tokens, "", &ParseError{Offset: scanner.offset, Err: ErrTooManyTokens}
+		}
+
+		tok, err := scanner.Next()
 		if err != nil {
 			return tokens, "", err
 		}
 
-		tokens = append(tokens, tok)
-
-		if strings.ContainsRune(scanner.UserTokens, rune(delim)) {
-			tokens = append(tokens, string(delim))
+		if options && !scanner.RawOptions && tok.Value == "--" && tok.End != EndUserToken {
+			// end of options
+			break
 		}
 
+		tokens = scanner.appendToken(tokens, tok)
 	}
 
-	rest, err := ioutil.ReadAll(scanner.in)
-	if err == io.EOF {
-		err = nil
+	start := scanner.offset
+	rest, err := scanner.readAll()
+	trimmed := strings.TrimSpace(rest)
+	if trimmed != "" {
+		scanner.restOffset = start + strings.Index(rest, trimmed)
 	}
-	return tokens, strings.TrimSpace(string(rest)), err
+	return tokens, trimmed, err
 }
 
 // GetArgsOption is the type for GetArgs options
 type GetArgsOption func(s *Scanner)
 
+// RawOptions makes GetOptionTokens (and GetOptions) return "--" as an option and continue,
+// instead of stopping there
+func RawOptions() GetArgsOption {
+	return func(s *Scanner) {
+		s.RawOptions = true
+	}
+}
+
 // InfieldBrackets enable processing of in-field brackets (i.e. name={"values in brackets"})
 func InfieldBrackets() GetArgsOption {
 	return func(s *Scanner) {
@@ -306,6 +1570,211 @@ func InfieldBrackets() GetArgsOption {
 	}
 }
 
+// DetectUTF16 enables decoding of UTF-16LE/BE input, recognized by its byte order mark
+func DetectUTF16() GetArgsOption {
+	return func(s *Scanner) {
+		s.UTF16 = true
+	}
+}
+
+// InvalidUTF8 sets the policy for invalid UTF-8 sequences in the input
+func InvalidUTF8(policy InvalidUTF8Policy) GetArgsOption {
+	return func(s *Scanner) {
+		s.InvalidUTF8 = policy
+	}
+}
+
+// ASCIISpaces restricts word separators to ASCII space, tab and newline (POSIX behavior),
+// instead of all Unicode white spaces (i.e. non-breaking spaces are part of a word)
+func ASCIISpaces() GetArgsOption {
+	return func(s *Scanner) {
+		s.ASCIISpaces = true
+	}
+}
+
+// SmartQuotes enables typographic quotes (“ ” ‘ ’) as quote pairs, as commonly found in text
+// pasted from chat applications and word processors
+func SmartQuotes() GetArgsOption {
+	return func(s *Scanner) {
+		s.SmartQuotes = true
+	}
+}
+
+// QuoteChars sets the quote characters (default QUOTE_CHARS), i.e. only `"` for cmd.exe
+func QuoteChars(quotes string) GetArgsOption {
+	return func(s *Scanner) {
+		s.QuoteChars = quotes
+	}
+}
+
+// EscapeChar sets the escape character (default ESCAPE_CHAR)
+func EscapeChar(c rune) GetArgsOption {
+	return func(s *Scanner) {
+		s.EscapeChar = c
+	}
+}
+
+// NoEscape disables escape processing (i.e. for Windows paths like C:\temp\new)
+func NoEscape() GetArgsOption {
+	return func(s *Scanner) {
+		s.NoEscape = true
+	}
+}
+
+// POSIXQuotes disables escape processing inside single quotes, as POSIX shells do
+// (i.e. 'a\nb' is the 4 characters a, \, n, b)
+func POSIXQuotes() GetArgsOption {
+	return func(s *Scanner) {
+		s.POSIXQuotes = true
+	}
+}
+
+// QuotedNewline sets the policy for an escaped newline inside double quotes
+func QuotedNewline(policy QuotedNewlinePolicy) GetArgsOption {
+	return func(s *Scanner) {
+		s.QuotedNewline = policy
+	}
+}
+
+// Comments enables comment processing, according to the specified policy
+func Comments(policy CommentPolicy) GetArgsOption {
+	return func(s *Scanner) {
+		s.Comments = policy
+	}
+}
+
+// SymbolChars sets the list of characters that, at the beginning of a word, are returned as single tokens
+// (default SYMBOL_CHARS)
+func SymbolChars(symbols string) GetArgsOption {
+	return func(s *Scanner) {
+		s.SymbolChars = symbols
+	}
+}
+
+// NoSymbols disables symbol processing
+func NoSymbols() GetArgsOption {
+	return func(s *Scanner) {
+		s.NoSymbols = true
+	}
+}
+
+// ShellOperators enables recognition of the common multi-character shell operators (see OPERATORS)
+func ShellOperators() GetArgsOption {
+	return func(s *Scanner) {
+		s.Operators = OPERATORS
+	}
+}
+
+// EnvAssignments makes ParseArgs return leading environment assignments (FOO=bar BAZ="x y" cmd ...) in Args.Env,
+// as a shell would, instead of as arguments. Quoted values are part of the assignment, even without Concat,
+// while a quoted name ("FOO=bar") is not an assignment.
+func EnvAssignments() GetArgsOption {
+	return func(s *Scanner) {
+		s.EnvAssignments = true
+	}
+}
+
+// CommentTokens returns comments as tokens of type CommentToken (with their position, if TrackSegments is set),
+// so that they can be inspected or preserved. Comments must be enabled (see Comments).
+func CommentTokens() GetArgsOption {
+	return func(s *Scanner) {
+		s.CommentTokens = true
+	}
+}
+
+// Concat enables shell-style concatenation of adjacent segments,
+// i.e. foo"bar baz"qux is the single argument foobar bazqux and --opt="a b" is --opt=a b
+func Concat() GetArgsOption {
+	return func(s *Scanner) {
+		s.Concat = true
+	}
+}
+
+// TrackSegments enables the collection of token segments (text, quoting style and offsets),
+// available in Token.Segments
+func TrackSegments() GetArgsOption {
+	return func(s *Scanner) {
+		s.TrackSegments = true
+	}
+}
+
+// MaxTokens limits the number of tokens returned by the Scanner (see ErrTooManyTokens)
+func MaxTokens(n int) GetArgsOption {
+	return func(s *Scanner) {
+		s.MaxTokens = n
+	}
+}
+
+// MaxTokenSize limits the size of a single token, comment or remainder read by the Scanner (see ErrTokenTooLarge),
+// so that untrusted input can't make it buffer an unbounded amount of data
+func MaxTokenSize(n int) GetArgsOption {
+	return func(s *Scanner) {
+		s.MaxTokenSize = n
+	}
+}
+
+// Strict enables strict mode (see GetArgsStrict)
+func Strict() GetArgsOption {
+	return func(s *Scanner) {
+		s.Strict = true
+	}
+}
+
+// CEscapes enables processing of C-style escape sequences (\n, \t, \xHH, \uHHHH, \nnn, ...)
+func CEscapes() GetArgsOption {
+	return func(s *Scanner) {
+		s.CEscapes = true
+	}
+}
+
+// QuotedCEscapes enables processing of C-style escape sequences only inside double quotes (see CEscapes)
+func QuotedCEscapes() GetArgsOption {
+	return func(s *Scanner) {
+		s.QuotedCEscapes = true
+	}
+}
+
+// NoBrackets disables bracket processing ({...}, [...] and (...) are not single tokens)
+func NoBrackets() GetArgsOption {
+	return func(s *Scanner) {
+		s.NoBrackets = true
+	}
+}
+
+// AngleBrackets enables <...> as a bracket pair (i.e. <user@host> or <placeholder>).
+// A < followed by a space or by <, > or & is still a symbol, so that "cat < file" or "cmd <<EOF" are not affected.
+func AngleBrackets() GetArgsOption {
+	return func(s *Scanner) {
+		s.AngleBrackets = true
+	}
+}
+
+// DOSOptions makes ParseArgs recognize DOS-style options (/flag, /flag:value and /flag=value) instead of -flag.
+// It also disables escape processing, since backslash is the path separator in DOS command lines.
+func DOSOptions() GetArgsOption {
+	return func(s *Scanner) {
+		s.DOSOptions = true
+		s.NoEscape = true
+	}
+}
+
+// EvalParens calls eval for each parenthesized token (i.e. "(1 + 2)", including the parentheses)
+// and replaces the token with the returned value. An error from eval is returned as a ParseError
+func EvalParens(eval func(expr string) (string, error)) GetArgsOption {
+	return func(s *Scanner) {
+		s.EvalParens = eval
+	}
+}
+
+// Classify calls classify for each token returned by the scanner. The returned tokens are returned instead,
+// so that classify can change the token Type (i.e. to a CustomToken value), transform the token,
+// split it (returning multiple tokens) or drop it (returning no tokens)
+func Classify(classify func(tok Token) []Token) GetArgsOption {
+	return func(s *Scanner) {
+		s.Classify = classify
+	}
+}
+
 // UserTokens allows a client to define a list of tokens (runes) that can be used as additional separators
 func UserTokens(t string) GetArgsOption {
 	return func(s *Scanner) {
@@ -313,6 +1782,14 @@ func UserTokens(t string) GetArgsOption {
 	}
 }
 
+// DropEmptyTokens drops the unquoted empty tokens that end at a user token (i.e. "a ,b" returns "a", ",", "b"
+// instead of "a", "", ",", "b"). Quoted empty tokens are always returned.
+func DropEmptyTokens() GetArgsOption {
+	return func(s *Scanner) {
+		s.DropEmpty = true
+	}
+}
+
 func getScanner(line string, options ...GetArgsOption) *Scanner {
 	scanner := NewScannerString(line)
 
@@ -330,6 +1807,57 @@ func GetArgs(line string, options ...GetArgsOption) (args []string) {
 	return
 }
 
+// GetArgsStrict parses the input line into an array of arguments, in strict mode.
+//
+// Strict mode guarantees that:
+//   - the input is never silently truncated: either all of it is returned as arguments or an error is returned
+//   - every malformed input (unterminated quote, unbalanced or mismatched bracket, trailing escape character,
+//     invalid UTF-8 unless the policy is PassInvalid) returns an error (see ParseError, InvalidUTF8Error)
+//   - the arguments never contain unprocessed escape characters or quotes that were meant to be removed
+//     (in-field quotes and brackets, and the content of bracketed arguments, are preserved as documented)
+func GetArgsStrict(line string, options ...GetArgsOption) ([]string, error) {
+	scanner := getScanner(line, options...)
+	scanner.Strict = true
+
+	args, _, err := scanner.GetTokensN(0)
+	if err == io.EOF {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return args, nil
+}
+
+// GetArgsRest parses (in strict mode) up to n leading arguments, stopping early at "--",
+// and returns the remaining text verbatim (unsplit, with quotes and escapes preserved).
+// This is useful for commands like "!note add -- free form text".
+func GetArgsRest(line string, n int, options ...GetArgsOption) (args []string, rest string, err error) {
+	scanner := getScanner(line, options...)
+	scanner.Strict = true
+	args = []string{}
+
+	for len(args) < n {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			return args, "", nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		if tok.Value == "--" && !tok.Quoted {
+			break
+		}
+
+		args = scanner.appendToken(args, tok)
+	}
+
+	rest, err = scanner.readAll()
+	return args, strings.TrimLeftFunc(rest, scanner.isSpace), err
+}
+
 // Parse the input line into an array of max n arguments.
 // If n <= 1 this is equivalent to calling GetArgs.
 func GetArgsN(line string, n int, options ...GetArgsOption) []string {
@@ -344,15 +1872,65 @@ func GetArgsN(line string, n int, options ...GetArgsOption) []string {
 	return args
 }
 
+// GetArgsNOffset parses up to n-1 arguments (as GetArgsN) and returns the byte offset in line where
+// the remainder begins (len(line) if there is no remainder), so that line[offset:] is the remainder
+// with the original quoting and spacing
+func GetArgsNOffset(line string, n int, options ...GetArgsOption) (args []string, offset int) {
+	scanner := getScanner(line, options...)
+	if n > 0 {
+		n = n - 1
+	}
+	args, _, _ = scanner.GetTokensN(n)
+	return args, restOffset(scanner, line)
+}
+
+// GetOptions returns the leading options in line (see Scanner.GetOptionTokens) and the remainder of the line
 func GetOptions(line string, scanOptions ...GetArgsOption) (options []string, rest string) {
 	scanner := getScanner(line, scanOptions...)
 	options, rest, _ = scanner.GetOptionTokens()
 	return
 }
 
+// GetOptionsOffset is like GetOptions, but returns the byte offset in line where the remainder begins
+// (len(line) if there is no remainder) instead of the remainder
+func GetOptionsOffset(line string, scanOptions ...GetArgsOption) (options []string, offset int) {
+	scanner := getScanner(line, scanOptions...)
+	options, _, _ = scanner.GetOptionTokens()
+	return options, restOffset(scanner, line)
+}
+
+func restOffset(scanner *Scanner, line string) int {
+	if offset := scanner.RestOffset(); offset >= 0 && offset <= len(line) {
+		return offset
+	}
+
+	return len(line)
+}
+
+// Position is the position of an option in the input
+type Position struct {
+	Index  int // index of the token (starting from 0)
+	Offset int // byte offset of the token in the input
+}
+
 type Args struct {
 	Options   map[string]string
+	HasValue  map[string]bool     // options set with an explicit value (--name=value or --name=)
+	Positions map[string]Position // where each option appeared in the input (the last occurrence)
 	Arguments []string
+	Warnings  []string // warnings generated while parsing (see Spec.Parse)
+	Verbatim  string   // the raw text after "--" (unsplit, with quotes and escapes preserved)
+	Env       []string // leading NAME=value assignments (see EnvAssignments)
+
+	short map[string]bool // options with a single dash and no value (candidates for attached values, see Spec.Parse)
+	long  map[string]bool // options with a double dash (candidates for abbreviations, see Spec.Parse)
+}
+
+// Lookup returns the value of an option, if the option is present and if the value was explicitly set.
+// This distinguishes --name (present, not explicit) from --name= (present, explicit empty value).
+func (a Args) Lookup(name string) (value string, present, explicit bool) {
+	value, present = a.Options[name]
+	return value, present, a.HasValue[name]
 }
 
 func (a Args) GetOption(name, def string) string {
@@ -382,25 +1960,200 @@ func (a Args) GetBoolOption(name string, def bool) bool {
 	return def
 }
 
+// GetStringsOption returns the value of a list option, split on commas.
+// Quotes and escapes are respected, so that --tags='a,b',c returns ["a,b", "c"]
+// (note that with the Concat option the quotes are removed by ParseArgs, before splitting)
+func (a Args) GetStringsOption(name string, def []string) []string {
+	val, ok := a.Options[name]
+	if !ok {
+		return def
+	}
+
+	scanner := NewScannerString(val)
+	scanner.UserTokens = ","
+	scanner.Concat = true
+	scanner.NoSymbols = true
+	scanner.NoBrackets = true
+
+	values := []string{}
+	words := []string{}
+
+	for {
+		tok, err := scanner.Next()
+		if err != nil && err != io.EOF {
+			break
+		}
+
+		if tok.Value != "" || tok.Quoted {
+			words = append(words, tok.Value)
+		}
+
+		if err == io.EOF || tok.End == EndUserToken || tok.End == EndEOF {
+			if len(words) > 0 {
+				values = append(values, strings.Join(words, " "))
+				words = words[:0]
+			}
+		}
+
+		if err == io.EOF || tok.End == EndEOF {
+			break
+		}
+	}
+
+	return values
+}
+
+// SplitEnv separates the leading environment assignments (NAME=value, where NAME is a valid variable name)
+// from the rest of the arguments
+func SplitEnv(args []string) (env, rest []string) {
+	env = []string{}
+
+	for i, arg := range args {
+		if !isAssignment(arg) {
+			return env, args[i:]
+		}
+
+		env = append(env, arg)
+	}
+
+	return env, []string{}
+}
+
+// isEnvAssignment returns true if the token is in the form NAME=value, with NAME not quoted (as "NAME=value")
+func isEnvAssignment(tok Token) bool {
+	if len(tok.Segments) == 0 || tok.Segments[0].Quote != NO_QUOTE || !isAssignment(tok.Value) {
+		return false
+	}
+
+	return strings.IndexByte(tok.Value, '=') < len(tok.Segments[0].Text)
+}
+
+// isAssignment returns true if arg is in the form NAME=value
+func isAssignment(arg string) bool {
+	n := strings.IndexByte(arg, '=')
+	if n <= 0 {
+		return false
+	}
+
+	for i, c := range arg[:n] {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseArgs splits the line and separates the leading options (-name, --name, -name=value) from the arguments.
+// Option parsing stops at "--" or at the first positional argument: everything after it (including arguments
+// that look like options) is returned in Arguments, as needed by wrapper commands like time, env or exec.
 func ParseArgs(line string, options ...GetArgsOption) (parsed Args) {
-	parsed = Args{Options: map[string]string{}, Arguments: []string{}}
-	args := GetArgs(line, options...)
+	parsed = Args{
+		Options:   map[string]string{},
+		HasValue:  map[string]bool{},
+		Positions: map[string]Position{},
+		Arguments: []string{},
+		short:     map[string]bool{},
+		long:      map[string]bool{},
+	}
+
+	scanner := getScanner(line, options...)
+	scanner.TrackSegments = true
+	args := []string{}
+	starts := []int{} // start offset of each argument
+	ends := []int{}   // end offset of each argument
+
+	env := scanner.EnvAssignments // reading the leading assignments
+	nenv := 0                     // number of leading assignments
+	concat := scanner.Concat
+
+	for {
+		if scanner.MaxTokens > 0 && len(args) >= scanner.MaxTokens {
+			break // as GetArgs (see ErrTooManyTokens)
+		}
+
+		if env {
+			// the assignment value can be quoted (BAZ="x y"), as with Concat
+			scanner.Concat = concat || isAssignment(strings.TrimLeftFunc(line[scanner.offset:], unicode.IsSpace))
+		}
+
+		tok, err := scanner.Next()
+		if err != nil {
+			break
+		}
+
+		n := len(args)
+		args = scanner.appendToken(args, tok)
+
+		if env {
+			if n == nenv && len(args) > n && isEnvAssignment(tok) {
+				nenv++
+			}
+
+			if env = nenv == len(args); !env {
+				scanner.Concat = concat
+			}
+		}
+
+		for i := n; i < len(args); i++ {
+			start := scanner.offset - utf8.RuneLen(rune(tok.Delim)) // user token
+			if i == n && len(tok.Segments) > 0 {
+				start = tok.Segments[0].Start
+			}
+
+			starts = append(starts, start)
+			ends = append(ends, scanner.offset)
+		}
+	}
+
 	if len(args) == 0 {
 		return
 	}
 
-	for len(args) > 0 {
+	i := 0
+
+	if scanner.EnvAssignments {
+		parsed.Env, args = append([]string{}, args[:nenv]...), args[nenv:]
+		i = nenv
+	}
+
+	for ; len(args) > 0; i++ {
 		arg := args[0]
 
+		if scanner.DOSOptions {
+			if len(arg) < 2 || arg[0] != '/' {
+				break
+			}
+
+			args = args[1:]
+			arg = arg[1:]
+
+			if n := strings.IndexAny(arg, ":="); n >= 0 {
+				parsed.Options[arg[:n]] = arg[n+1:]
+				parsed.HasValue[arg[:n]] = true
+				parsed.Positions[arg[:n]] = Position{Index: i, Offset: starts[i]}
+			} else {
+				parsed.Options[arg] = ""
+				delete(parsed.HasValue, arg)
+				parsed.Positions[arg] = Position{Index: i, Offset: starts[i]}
+			}
+			continue
+		}
+
 		if !strings.HasPrefix(arg, "-") {
 			break
 		}
 
 		args = args[1:]
 		if arg == "--" { // stop parsing options
+			if ends[i] <= len(line) {
+				parsed.Verbatim = strings.TrimLeftFunc(line[ends[i]:], scanner.isSpace)
+			}
 			break
 		}
 
+		short := !strings.HasPrefix(arg, "--")
+
 		arg = strings.TrimLeft(arg, "-")
 		if strings.Contains(arg, "=") {
 			parts := strings.SplitN(arg, "=", 2)
@@ -408,28 +2161,21 @@ func ParseArgs(line string, options ...GetArgsOption) (parsed Args) {
 			value := parts[1]
 
 			parsed.Options[key] = value
+			parsed.HasValue[key] = true
+			parsed.Positions[key] = Position{Index: i, Offset: starts[i]}
+			parsed.long[key] = !short
 		} else {
 			parsed.Options[arg] = ""
+			delete(parsed.HasValue, arg)
+			parsed.Positions[arg] = Position{Index: i, Offset: starts[i]}
+			parsed.long[arg] = !short
+
+			if short {
+				parsed.short[arg] = true
+			}
 		}
 	}
 
 	parsed.Arguments = args
 	return
 }
-
-// Create a new FlagSet to be used with ParseFlags
-func NewFlags(name string) *flag.FlagSet {
-	flags := flag.NewFlagSet(name, flag.ContinueOnError)
-
-	flags.Usage = func() {
-		fmt.Printf("Usage of %s:\n", name)
-		flags.PrintDefaults()
-	}
-
-	return flags
-}
-
-// Parse the input line through the (initialized) FlagSet
-func ParseFlags(flags *flag.FlagSet, line string) error {
-	return flags.Parse(GetArgs(line))
-}
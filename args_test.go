@@ -1,7 +1,12 @@
 package args
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -24,7 +29,7 @@ func TestScanner(test *testing.T) {
 			break
 		}
 
-		test.Logf("%q %q", delim, token)
+		test.Logf("%q %q", rune(delim), token)
 	}
 }
 
@@ -39,7 +44,7 @@ func TestScannerInfieldBrackets(test *testing.T) {
 			break
 		}
 
-		test.Logf("%q %q", delim, token)
+		test.Logf("%q %q", rune(delim), token)
 	}
 }
 
@@ -57,15 +62,383 @@ func TestScannerDots(test *testing.T) {
 
 		res = append(res, token)
 		if delim != '.' {
-			test.Logf("delimiter: %q", delim)
+			test.Logf("delimiter: %q", rune(delim))
 			break
 		}
 	}
 
-	var rest [64]byte
-	n, _ := scanner.in.Read(rest[:])
+	rest, _ := scanner.readAll()
 
-	test.Log("tokens:", res, "remain:", string(rest[:n]))
+	test.Log("tokens:", res, "remain:", rest)
+}
+
+type onlyRunes struct {
+	r *strings.Reader
+}
+
+func (o onlyRunes) ReadRune() (rune, int, error) {
+	return o.r.ReadRune()
+}
+
+func TestScannerRunes(test *testing.T) {
+	expected := GetArgs(TEST_STRING)
+
+	for _, scanner := range []*Scanner{
+		NewScanner(strings.NewReader(TEST_STRING)),
+		NewScanner(bufio.NewReader(strings.NewReader(TEST_STRING))),
+		NewScannerRunes(onlyRunes{strings.NewReader(TEST_STRING)}),
+	} {
+		tokens, err := scanner.GetTokens()
+		if err != nil && err != io.EOF {
+			test.Fatal(err)
+		}
+
+		if fmt.Sprintf("%q", tokens) != fmt.Sprintf("%q", expected) {
+			test.Errorf("expected %q got %q", expected, tokens)
+		}
+	}
+}
+
+func TestBOM(test *testing.T) {
+	args := GetArgs("\uFEFFecho hello")
+	if len(args) != 2 || args[0] != "echo" {
+		test.Errorf("expected [echo hello] got %q", args)
+	}
+
+	le := "\xFF\xFEe\x00c\x00h\x00o\x00 \x00h\x00\xe9\x00"
+	be := "\xFE\xFF\x00e\x00c\x00h\x00o\x00 \x00h\x00\xe9"
+
+	for _, s := range []string{le, be} {
+		args := GetArgs(s, DetectUTF16())
+		if len(args) != 2 || args[0] != "echo" || args[1] != "h\u00e9" {
+			test.Errorf("expected [echo h\u00e9] got %q", args)
+		}
+	}
+
+	args = GetArgs("echo hello", DetectUTF16())
+	if len(args) != 2 || args[1] != "hello" {
+		test.Errorf("expected [echo hello] got %q", args)
+	}
+}
+
+func TestInvalidUTF8(test *testing.T) {
+	line := "one t\xffo three"
+
+	if args := GetArgs(line); args[1] != "t\uFFFDo" {
+		test.Errorf("expected replacement character, got %q", args[1])
+	}
+
+	if args := GetArgs(line, InvalidUTF8(PassInvalid)); args[1] != "t\xffo" {
+		test.Errorf("expected invalid byte, got %q", args[1])
+	}
+
+	scanner := NewScannerString(line)
+	scanner.InvalidUTF8 = RejectInvalid
+
+	_, err := scanner.GetTokens()
+	if e, ok := err.(*InvalidUTF8Error); !ok || e.Offset != 5 {
+		test.Errorf("expected InvalidUTF8Error at 5, got %v", err)
+	}
+}
+
+func TestASCIISpaces(test *testing.T) {
+	line := "copy file\u00a0name.txt\tdest"
+
+	if args := GetArgs(line); len(args) != 4 {
+		test.Errorf("expected 4 arguments, got %q", args)
+	}
+
+	if args := GetArgs(line, ASCIISpaces()); len(args) != 3 || args[1] != "file\u00a0name.txt" {
+		test.Errorf("expected 3 arguments, got %q", args)
+	}
+}
+
+func TestSmartQuotes(test *testing.T) {
+	args := GetArgs("say \u201Chello world\u201D \u2018single\u2019", SmartQuotes())
+	if len(args) != 3 || args[1] != "hello world" || args[2] != "single" {
+		test.Errorf("unexpected arguments %q", args)
+	}
+
+	args = GetArgs("say \u201Chello world\u201D")
+	if len(args) != 3 {
+		test.Errorf("expected 3 arguments, got %q", args)
+	}
+}
+
+func TestEscapeChar(test *testing.T) {
+	line := `copy C:\temp\new "D:\my files"`
+
+	args := GetArgs(line, NoEscape())
+	if len(args) != 3 || args[1] != `C:\temp\new` || args[2] != `D:\my files` {
+		test.Errorf("unexpected arguments %q", args)
+	}
+
+	args = GetArgs("echo a^ b `x\\y`", EscapeChar('^'))
+	if len(args) != 3 || args[1] != "a b" || args[2] != "x\\y" {
+		test.Errorf("unexpected arguments %q", args)
+	}
+}
+
+func TestPOSIXQuotes(test *testing.T) {
+	line := `printf 'a\nb' "c\"d"`
+
+	args := GetArgs(line, POSIXQuotes())
+	if len(args) != 3 || args[1] != `a\nb` || args[2] != `c"d` {
+		test.Errorf("unexpected arguments %q", args)
+	}
+
+	args = GetArgs(line)
+	if len(args) != 3 || args[1] != `anb` {
+		test.Errorf("unexpected arguments %q", args)
+	}
+}
+
+func TestQuotedNewline(test *testing.T) {
+	line := "echo \"one \\\ntwo\""
+
+	for policy, expected := range map[QuotedNewlinePolicy]string{
+		EscapeNewline:  "one \ntwo",
+		JoinNewline:    "one two",
+		LiteralNewline: "one \\\ntwo",
+	} {
+		args := GetArgs(line, QuotedNewline(policy))
+		if len(args) != 2 || args[1] != expected {
+			test.Errorf("policy %v: expected %q got %q", policy, expected, args)
+		}
+	}
+}
+
+func TestComments(test *testing.T) {
+	line := "cat file#1 # a comment\n#another comment\nnext 'line#2' x#y"
+
+	for policy, expected := range map[CommentPolicy]string{
+		WordStartComments: `["cat" "file#1" "next" "line#2" "x#y"]`,
+		AnywhereComments:  `["cat" "file" "next" "line#2" "x"]`,
+	} {
+		if args := fmt.Sprintf("%q", GetArgs(line, Comments(policy))); args != expected {
+			test.Errorf("policy %v: expected %v got %v", policy, expected, args)
+		}
+	}
+}
+
+func TestSymbolChars(test *testing.T) {
+	line := "cat file |grep x >out # done"
+
+	if args := fmt.Sprintf("%q", GetArgs(line)); args != `["cat" "file" "|" "grep" "x" ">" "out" "#" "done"]` {
+		test.Errorf("unexpected arguments %v", args)
+	}
+
+	if args := fmt.Sprintf("%q", GetArgs(line, SymbolChars("|"))); args != `["cat" "file" "|" "grep" "x" ">out" "#" "done"]` {
+		test.Errorf("unexpected arguments %v", args)
+	}
+
+	if args := fmt.Sprintf("%q", GetArgs(line, NoSymbols())); args != `["cat" "file" "|grep" "x" ">out" "#" "done"]` {
+		test.Errorf("unexpected arguments %v", args)
+	}
+}
+
+func TestEndReason(test *testing.T) {
+	scanner := NewScannerString(`one "two" {three} | four`)
+
+	expected := []EndReason{EndSpace, EndQuote, EndBracket, EndSymbol, EndEOF}
+
+	for i := 0; ; i++ {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			if i != len(expected) {
+				test.Errorf("expected %d tokens, got %d", len(expected), i)
+			}
+			break
+		}
+
+		if i < len(expected) && tok.End != expected[i] {
+			test.Errorf("token %q: expected %v got %v", tok.Value, expected[i], tok.End)
+		}
+	}
+}
+
+func TestOperators(test *testing.T) {
+	scanner := NewScannerString("make >>log 2>&1 && echo ok || echo 2 >x")
+	scanner.Operators = OPERATORS
+
+	expected := []Token{
+		{Value: "make"}, {Value: ">>", Type: OperatorToken}, {Value: "log"},
+		{Value: "2>&1", Type: OperatorToken}, {Value: "&&", Type: OperatorToken},
+		{Value: "echo"}, {Value: "ok"}, {Value: "||", Type: OperatorToken},
+		{Value: "echo"}, {Value: "2"}, {Value: ">", Type: SymbolToken}, {Value: "x"},
+	}
+
+	for _, exp := range expected {
+		tok, err := scanner.Next()
+		if err != nil {
+			test.Fatal(err)
+		}
+
+		if tok.Value != exp.Value || tok.Type != exp.Type {
+			test.Errorf("expected %q (%v) got %q (%v)", exp.Value, exp.Type, tok.Value, tok.Type)
+		}
+	}
+}
+
+func TestEmptyArgs(test *testing.T) {
+	for line, expected := range map[string]string{
+		`set-title ""`:   `["set-title" ""]`,
+		`a '' "" b`:      `["a" "" "" "b"]`,
+		`x = "" = y`:     `["x" "=" "" "=" "y"]`,
+		`x=y ""=''`:      `["x" "=" "y" "" "=" ""]`,
+		`name=""`:        `["name" "=" ""]`,
+		`"" trailing ""`: `["" "trailing" ""]`,
+	} {
+		if args := fmt.Sprintf("%q", GetArgs(line, UserTokens("="), DropEmptyTokens())); args != expected {
+			test.Errorf("%v: expected %v got %v", line, expected, args)
+		}
+	}
+
+	// by default the unquoted empty token before a user token is returned
+	for line, expected := range map[string]string{
+		`a ,b`:     `["a" "" "," "b"]`,
+		`a,,b`:     `["a" "," "" "," "b"]`,
+		`"" , ""`:  `["" "" "," ""]`,
+		`a , "" b`: `["a" "" "," "" "b"]`,
+	} {
+		if args := fmt.Sprintf("%q", GetArgs(line, UserTokens(","))); args != expected {
+			test.Errorf("%v: expected %v got %v", line, expected, args)
+		}
+	}
+
+	scanner := NewScannerString(`say "" ''`)
+	scanner.Next()
+
+	for i := 0; i < 2; i++ {
+		if tok, err := scanner.Next(); err != nil || tok.Value != "" || !tok.Quoted {
+			test.Errorf("expected empty quoted token, got %#v %v", tok, err)
+		}
+	}
+}
+
+func TestConcat(test *testing.T) {
+	line := `foo"bar baz"qux --opt="a b" 'x'"y"z ""'' "end"`
+
+	if args := fmt.Sprintf("%q", GetArgs(line, Concat())); args != `["foobar bazqux" "--opt=a b" "xyz" "" "end"]` {
+		test.Errorf("unexpected arguments %v", args)
+	}
+
+	if args := fmt.Sprintf("%q", GetArgs(`""`, Concat())); args != `[""]` {
+		test.Errorf("unexpected arguments %v", args)
+	}
+}
+
+func TestSegments(test *testing.T) {
+	scanner := NewScannerString(`ls foo"bar baz"\ qux 'x' {"a":1}`)
+	scanner.Concat = true
+	scanner.TrackSegments = true
+
+	expected := [][]Segment{
+		{{"ls", NO_QUOTE, 0, 2}},
+		{{"foo", NO_QUOTE, 3, 6}, {"bar baz", '"', 6, 15}, {" qux", NO_QUOTE, 15, 20}},
+		{{"x", '\'', 21, 24}},
+		{{`{"a":1}`, '{', 25, 32}},
+	}
+
+	for _, exp := range expected {
+		tok, err := scanner.Next()
+		if err != nil {
+			test.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(tok.Segments, exp) {
+			test.Errorf("expected %v got %v", exp, tok.Segments)
+		}
+	}
+}
+
+func TestGetArgsStrict(test *testing.T) {
+	if args, err := GetArgsStrict(`cp "my file" {"a": [1]} dest\ dir`); err != nil || len(args) != 4 {
+		test.Errorf("unexpected result %q %v", args, err)
+	}
+
+	for line, expected := range map[string]error{
+		`echo "unterminated`: ErrUnterminatedQuote,
+		`echo trailing\`:     ErrTrailingEscape,
+		`set {"a": [1}`:      ErrMismatchedBracket,
+		`set {"a": 1`:        ErrUnbalancedBracket,
+		"echo \xff":          ErrInvalidUTF8,
+	} {
+		if args, err := GetArgsStrict(line); !errors.Is(err, expected) || args != nil {
+			test.Errorf("%v: expected %v got %q %v", line, expected, args, err)
+		}
+	}
+}
+
+func FuzzGetArgsStrict(f *testing.F) {
+	for _, s := range []string{TEST_STRING, TEST_BRACKETS, TEST_INFIELD, PARSE_STRING, `a "b`, `{[}`, `x\`} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(test *testing.T, line string) {
+		args, err := GetArgsStrict(line)
+		diags := Validate(line)
+
+		if err != nil {
+			var pe *ParseError
+			var ue *InvalidUTF8Error
+
+			if !errors.As(err, &pe) && !errors.As(err, &ue) {
+				test.Errorf("unexpected error type %#v", err)
+			}
+			if args != nil {
+				test.Errorf("unexpected arguments with error: %q", args)
+			}
+			return
+		}
+
+		for _, d := range diags {
+			if d.Severity == SeverityError {
+				test.Errorf("no error for malformed input %q: %v", line, d)
+			}
+		}
+
+		if len(args) == 0 && len(strings.TrimSpace(line)) > 0 && !strings.HasPrefix(strings.TrimSpace(line), "\uFEFF") {
+			test.Errorf("input %q returned no arguments", line)
+		}
+	})
+}
+
+func TestGetArgsRest(test *testing.T) {
+	for line, expected := range map[string]string{
+		`!note add "my list" buy 'milk' and "eggs"  `: `["!note" "add" "my list"] "buy 'milk' and \"eggs\"  "`,
+		`!note add -- "free" form`:                    `["!note" "add"] "\"free\" form"`,
+		`!note`:                                       `["!note"] ""`,
+	} {
+		args, rest, err := GetArgsRest(line, 3)
+		if err != nil {
+			test.Error(err)
+		}
+
+		if res := fmt.Sprintf("%q %q", args, rest); res != expected {
+			test.Errorf("expected %v got %v", expected, res)
+		}
+	}
+
+	if _, _, err := GetArgsRest(`!note "add`, 3); !errors.Is(err, ErrUnterminatedQuote) {
+		test.Errorf("expected ErrUnterminatedQuote, got %v", err)
+	}
+}
+
+func TestParseArgsVerbatim(test *testing.T) {
+	parsed := ParseArgs(`-v --name=x -- grep -e "a  b" 'c\d'`)
+
+	if parsed.Verbatim != `grep -e "a  b" 'c\d'` {
+		test.Errorf("unexpected verbatim %q", parsed.Verbatim)
+	}
+
+	if fmt.Sprintf("%q", parsed.Arguments) != `["grep" "-e" "a  b" "cd"]` {
+		test.Errorf("unexpected arguments %q", parsed.Arguments)
+	}
+
+	if parsed := ParseArgs("-v file"); parsed.Verbatim != "" {
+		test.Errorf("unexpected verbatim %q", parsed.Verbatim)
+	}
 }
 
 func TestGetArgs(test *testing.T) {
@@ -132,6 +505,344 @@ func TestUserTokens(test *testing.T) {
 	}
 }
 
+func TestAngleBrackets(test *testing.T) {
+	for line, expected := range map[string][]string{
+		`mail <joe@example.com> <"Joe Smith" <joe>>`: {"mail", "<joe@example.com>", `<"Joe Smith" <joe>>`},
+		`cat < file > out`:                           {"cat", "<", "file", ">", "out"},
+		`cat <<EOF`:                                  {"cat", "<", "<EOF"},
+		`hello <name> x<y>`:                          {"hello", "<name>", "x<y>"},
+	} {
+		if args := GetArgs(line, AngleBrackets()); !reflect.DeepEqual(args, expected) {
+			test.Errorf("%v: expected %q got %q", line, expected, args)
+		}
+	}
+
+	if args := GetArgs(`mail <joe@example.com>`); !reflect.DeepEqual(args, []string{"mail", "<", "joe@example.com>"}) {
+		test.Errorf("unexpected angle brackets without option: %q", args)
+	}
+}
+
+func TestClassify(test *testing.T) {
+	const RefToken = CustomToken
+
+	classify := func(tok Token) []Token {
+		switch {
+		case tok.Value == "please":
+			return nil // drop
+
+		case strings.HasPrefix(tok.Value, "@"):
+			tok.Type = RefToken
+			tok.Value = tok.Value[1:]
+
+		case strings.Contains(tok.Value, ",") && !tok.Quoted:
+			var tokens []Token
+			for _, v := range strings.Split(tok.Value, ",") {
+				t := tok
+				t.Value = v
+				tokens = append(tokens, t)
+			}
+			return tokens
+		}
+
+		return []Token{tok}
+	}
+
+	scanner := NewScannerString(`please notify @joe a,b "c,d"`)
+	Classify(classify)(scanner)
+
+	var tokens []string
+	for {
+		tok, err := scanner.Next()
+		if err != nil {
+			break
+		}
+
+		tokens = append(tokens, fmt.Sprintf("%v:%v", tok.Type, tok.Value))
+	}
+
+	expected := []string{"word:notify", "custom(0):joe", "word:a", "word:b", "word:c,d"}
+	if !reflect.DeepEqual(tokens, expected) {
+		test.Errorf("expected %q got %q", expected, tokens)
+	}
+}
+
+func TestSkipLine(test *testing.T) {
+	scanner := NewScannerString("good one\nbad {\"a\": [1} 'quoted\nnewline' \\\nescaped\nnext line\n")
+	scanner.Strict = true
+
+	var lines [][]string
+	var line []string
+
+	for {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if err = scanner.SkipLine(); err != nil {
+				break
+			}
+
+			line = nil
+			continue
+		}
+
+		line = append(line, tok.Value)
+		if tok.Delim == '\n' {
+			lines = append(lines, line)
+			line = nil
+		}
+	}
+
+	expected := [][]string{{"good", "one"}, {"next", "line"}}
+	if !reflect.DeepEqual(lines, expected) {
+		test.Errorf("expected %q got %q", expected, lines)
+	}
+
+	if err := NewScannerString("no newline").SkipLine(); err != io.EOF {
+		test.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestLookup(test *testing.T) {
+	parsed := ParseArgs("--verbose --label= --name=joe -- --other")
+
+	for name, expected := range map[string]struct {
+		value             string
+		present, explicit bool
+	}{
+		"verbose": {"", true, false},
+		"label":   {"", true, true},
+		"name":    {"joe", true, true},
+		"other":   {"", false, false},
+	} {
+		value, present, explicit := parsed.Lookup(name)
+		if value != expected.value || present != expected.present || explicit != expected.explicit {
+			test.Errorf("%v: expected %v got %q %v %v", name, expected, value, present, explicit)
+		}
+	}
+}
+
+func TestParseArgsStopAtPositional(test *testing.T) {
+	parsed := ParseArgs(`-p env -i FOO=1 ls -l --color=auto -- x`)
+
+	if expected := map[string]string{"p": ""}; !reflect.DeepEqual(parsed.Options, expected) {
+		test.Errorf("expected %q got %q", expected, parsed.Options)
+	}
+
+	if expected := []string{"env", "-i", "FOO=1", "ls", "-l", "--color=auto", "--", "x"}; !reflect.DeepEqual(parsed.Arguments, expected) {
+		test.Errorf("expected %q got %q", expected, parsed.Arguments)
+	}
+
+	if parsed.Verbatim != "" {
+		test.Errorf("unexpected verbatim %q", parsed.Verbatim)
+	}
+}
+
+func TestRestOffset(test *testing.T) {
+	line := `cmd  arg1   "quoted  rest"  \ more  `
+
+	args, offset := GetArgsNOffset(line, 3)
+	if expected := []string{"cmd", "arg1"}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+	if rest := line[offset:]; rest != `"quoted  rest"  \ more  ` {
+		test.Errorf("unexpected rest %q", rest)
+	}
+
+	if _, offset := GetArgsNOffset("a b", 5); offset != 3 {
+		test.Errorf("expected offset 3 got %v", offset)
+	}
+
+	line = `-v --name=x   'é  rest' -x`
+	options, offset := GetOptionsOffset(line)
+	if expected := []string{"-v", "--name=x"}; !reflect.DeepEqual(options, expected) {
+		test.Errorf("expected %q got %q", expected, options)
+	}
+	if rest := line[offset:]; rest != `'é  rest' -x` {
+		test.Errorf("unexpected rest %q", rest)
+	}
+}
+
+func TestGetOptionsEnd(test *testing.T) {
+	tests := []struct {
+		line    string
+		options []string
+		rest    string
+	}{
+		{`-a --b=c -- -d e`, []string{"-a", "--b=c"}, "-d e"},
+		{`-a "-b" -c`, []string{"-a"}, `"-b" -c`},
+		{`-a --`, []string{"-a"}, ""},
+		{`-- -a`, []string{}, "-a"},
+	}
+
+	for _, t := range tests {
+		options, rest := GetOptions(t.line)
+		if !reflect.DeepEqual(options, t.options) || rest != t.rest {
+			test.Errorf("%q: expected %q %q got %q %q", t.line, t.options, t.rest, options, rest)
+		}
+	}
+
+	options, rest := GetOptions(`-a -- -b c`, RawOptions())
+	if expected := []string{"-a", "--", "-b"}; !reflect.DeepEqual(options, expected) || rest != "c" {
+		test.Errorf("expected %q %q got %q %q", expected, "c", options, rest)
+	}
+}
+
+func TestOptionPositions(test *testing.T) {
+	parsed := ParseArgs(`-v  "--name=x y" --number=abc -v|--x arg`, UserTokens("|"))
+
+	expected := map[string]Position{
+		"v":      {3, 30},
+		"name":   {1, 4},
+		"number": {2, 17},
+	}
+
+	if !reflect.DeepEqual(parsed.Positions, expected) {
+		test.Errorf("expected %v got %v", expected, parsed.Positions)
+	}
+
+	if args := []string{"|", "--x", "arg"}; !reflect.DeepEqual(parsed.Arguments, args) {
+		test.Errorf("expected %q got %q", args, parsed.Arguments)
+	}
+}
+
+func TestGetStringsOption(test *testing.T) {
+	parsed := ParseArgs(`--tags='a,b',c --names=joe\ smith,\ jane --empty= --one=x`)
+
+	for name, expected := range map[string][]string{
+		"tags":    {"a,b", "c"},
+		"names":   {"joe smith", "jane"},
+		"empty":   {},
+		"one":     {"x"},
+		"missing": {"default"},
+	} {
+		if values := parsed.GetStringsOption(name, []string{"default"}); !reflect.DeepEqual(values, expected) {
+			test.Errorf("%v: expected %q got %q", name, expected, values)
+		}
+	}
+}
+
+func TestDOSOptions(test *testing.T) {
+	parsed := ParseArgs(`/s /out:C:\Temp\out.txt /level=3 /q: -x "C:\Program Files" /y`, DOSOptions())
+
+	expected := map[string]string{"s": "", "out": `C:\Temp\out.txt`, "level": "3", "q": ""}
+	if !reflect.DeepEqual(parsed.Options, expected) {
+		test.Errorf("expected %q got %q", expected, parsed.Options)
+	}
+
+	if args := []string{"-x", `C:\Program Files`, "/y"}; !reflect.DeepEqual(parsed.Arguments, args) {
+		test.Errorf("expected %q got %q", args, parsed.Arguments)
+	}
+
+	if _, _, explicit := parsed.Lookup("q"); !explicit {
+		test.Errorf("expected explicit empty value for /q:")
+	}
+}
+
+func TestCommentTokens(test *testing.T) {
+	scanner := NewScannerString("# header\nls -l # list files\necho a#b\n# end")
+	Comments(AnywhereComments)(scanner)
+	CommentTokens()(scanner)
+	TrackSegments()(scanner)
+
+	var tokens []string
+	for {
+		tok, err := scanner.Next()
+		if err != nil {
+			break
+		}
+
+		if tok.Type == CommentToken {
+			seg := tok.Segments[0]
+			tokens = append(tokens, fmt.Sprintf("%q@%d:%d-%d", tok.Value, tok.Line, seg.Start, seg.End))
+		} else {
+			tokens = append(tokens, tok.Value)
+		}
+	}
+
+	expected := []string{`" header"@1:0-8`, "ls", "-l", `" list files"@2:15-27`, "echo", "a", `"b"@3:34-36`, `" end"@4:37-42`}
+	if !reflect.DeepEqual(tokens, expected) {
+		test.Errorf("expected %q got %q", expected, tokens)
+	}
+}
+
+func TestEnvAssignments(test *testing.T) {
+	parsed := ParseArgs(`FOO=bar BAZ="x y" _X1= -v --n=1 cmd A=b`, EnvAssignments(), Concat())
+
+	if expected := []string{"FOO=bar", "BAZ=x y", "_X1="}; !reflect.DeepEqual(parsed.Env, expected) {
+		test.Errorf("expected %q got %q", expected, parsed.Env)
+	}
+	if expected := map[string]string{"v": "", "n": "1"}; !reflect.DeepEqual(parsed.Options, expected) {
+		test.Errorf("expected %q got %q", expected, parsed.Options)
+	}
+	if expected := []string{"cmd", "A=b"}; !reflect.DeepEqual(parsed.Arguments, expected) {
+		test.Errorf("expected %q got %q", expected, parsed.Arguments)
+	}
+	if p := parsed.Positions["n"]; p.Index != 4 {
+		test.Errorf("expected index 4 got %v", p.Index)
+	}
+
+	if parsed := ParseArgs(`1X=a FOO=bar`, EnvAssignments()); len(parsed.Env) != 0 || len(parsed.Arguments) != 2 {
+		test.Errorf("unexpected env %q", parsed.Env)
+	}
+
+	if parsed := ParseArgs(`FOO=bar cmd`); len(parsed.Env) != 0 || parsed.Arguments[0] != "FOO=bar" {
+		test.Errorf("unexpected env without option: %q", parsed.Env)
+	}
+
+	// quoted values without Concat
+	parsed = ParseArgs(`FOO=bar BAZ="x y" Q='a'b"c" --n=1 cmd "x y"`, EnvAssignments())
+	if expected := []string{"FOO=bar", "BAZ=x y", "Q=abc"}; !reflect.DeepEqual(parsed.Env, expected) {
+		test.Errorf("expected %q got %q", expected, parsed.Env)
+	}
+	if expected := []string{"cmd", "x y"}; !reflect.DeepEqual(parsed.Arguments, expected) {
+		test.Errorf("expected %q got %q", expected, parsed.Arguments)
+	}
+	if p := parsed.Positions["n"]; p.Index != 3 || p.Offset != 28 {
+		test.Errorf("expected index 3 (offset 28) got %v", p)
+	}
+
+	// Concat is only implied for the assignments
+	if parsed = ParseArgs(`A="1" b"c"`, EnvAssignments()); parsed.Env[0] != "A=1" || parsed.Arguments[0] != `b"c"` {
+		test.Errorf("unexpected env %q arguments %q", parsed.Env, parsed.Arguments)
+	}
+
+	// a quoted name is not an assignment
+	for _, opts := range [][]GetArgsOption{{EnvAssignments()}, {EnvAssignments(), Concat()}} {
+		parsed = ParseArgs(`A=1 "FOO=bar" x`, opts...)
+		if len(parsed.Env) != 1 || !reflect.DeepEqual(parsed.Arguments, []string{"FOO=bar", "x"}) {
+			test.Errorf("unexpected env %q arguments %q", parsed.Env, parsed.Arguments)
+		}
+	}
+}
+
+func TestEvalParens(test *testing.T) {
+	eval := func(expr string) (string, error) {
+		switch expr {
+		case "(1 + 2)":
+			return "3", nil
+		case "(user)":
+			return "joe", nil
+		}
+		return "", errors.New("cannot evaluate " + expr)
+	}
+
+	args, err := GetArgsStrict(`echo (1 + 2) "(1 + 2)" {(user)} (user)`, EvalParens(eval))
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	if expected := []string{"echo", "3", "(1 + 2)", "{(user)}", "joe"}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+
+	var perr *ParseError
+	if _, err := GetArgsStrict(`echo (1 / 0)`, EvalParens(eval)); !errors.As(err, &perr) || perr.Offset != 5 {
+		test.Errorf("expected ParseError at offset 5, got %v", err)
+	}
+}
+
 func ExampleGetArgs() {
 	s := `one two three "double quotes" 'single quotes' arg\ with\ spaces "\"quotes\" in 'quotes'" '"quotes" in \'quotes'"`
 
@@ -158,29 +869,69 @@ func ExampleParseArgs() {
 	fmt.Println("arguments:", parsed.Arguments)
 	// Output:
 	// options: map[l: number:42 where:here]
-	// arguments: [-not-an-option- one two three |pipers piping]
+	// arguments: [-not-an-option- one two three | pipers piping]
 }
 
-func ExampleParseFlags() {
-	arguments := "-l --number=42 -where=here -- -not-an-option- one two three"
+func TestASCIIFastPath(test *testing.T) {
+	line := `ascii "then é" ü\ x`
 
-	flags := NewFlags("args")
+	scanner := NewScannerString(line)
+	scanner.TrackSegments = true
 
-	list := flags.Bool("l", false, "list something")
-	num := flags.Int("number", 0, "a number option")
-	where := flags.String("where", "", "a string option")
+	expected := []Token{
+		{Value: "ascii", Delim: ' ', End: EndSpace, Line: 1, Segments: []Segment{{"ascii", NO_QUOTE, 0, 5}}},
+		{Value: "then é", Delim: '"', End: EndQuote, Quoted: true, Line: 1, Segments: []Segment{{"then é", '"', 6, 15}}},
+		{Value: "ü x", End: EndEOF, Line: 1, Segments: []Segment{{"ü x", NO_QUOTE, 16, 21}}},
+	}
 
-	if err := ParseFlags(flags, arguments); err != nil {
-		fmt.Println(err)
-	} else {
-		fmt.Println("list:", *list)
-		fmt.Println("num:", *num)
-		fmt.Println("where:", *where)
-		fmt.Println("args:", flags.Args())
+	for _, e := range expected {
+		tok, err := scanner.Next()
+		if err != nil {
+			test.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(tok, e) {
+			test.Errorf("expected %+v got %+v", e, tok)
+		}
+	}
+}
+
+func BenchmarkGetArgs(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		GetArgs(`git commit --amend -m "fix the bug" --author=someone@example.com file1.go file2.go`)
+	}
+}
+
+func TestCopyFreeTokens(test *testing.T) {
+	line := `git status\ x "--short" --n=1 $$`
+
+	if args, expected := GetArgs(line), []string{"git", "status x", "--short", "--n=1", "$$"}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+
+	makeEscapes := func(s *Scanner) { s.MakeEscapes = true }
+	if args, expected := GetArgs(line, makeEscapes), []string{"git", "status x", "--short", "--n=1", "$"}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+
+	scanner := NewScannerString("git status --short")
+	if n := testing.AllocsPerRun(1, func() { scanner.Next() }); n > 0 {
+		test.Errorf("expected no allocations for unquoted tokens, got %v", n)
+	}
+}
+
+func TestParseArgsMaxTokens(test *testing.T) {
+	line := "-v --n=1 a b c d"
+
+	if args := GetArgs(line, MaxTokens(2)); len(args) != 2 {
+		test.Errorf("expected 2 tokens got %q", args)
+	}
+
+	parsed := ParseArgs(line, MaxTokens(3))
+	if expected := map[string]string{"v": "", "n": "1"}; !reflect.DeepEqual(parsed.Options, expected) {
+		test.Errorf("expected %v got %v", expected, parsed.Options)
+	}
+	if expected := []string{"a"}; !reflect.DeepEqual(parsed.Arguments, expected) {
+		test.Errorf("expected %q got %q", expected, parsed.Arguments)
 	}
-	// Output:
-	// list: true
-	// num: 42
-	// where: here
-	// args: [-not-an-option- one two three]
 }
@@ -0,0 +1,147 @@
+// Package argstest provides a corpus of canonical command lines and the expected splitting,
+// and a runner to verify that custom options, Specs or dialects preserve the core semantics of package args.
+package argstest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gobs/args"
+)
+
+// Case is a command line and the expected arguments
+type Case struct {
+	Name     string
+	Line     string
+	Expected []string
+}
+
+// Dialect is a set of scanner options and the cases specific to it
+type Dialect struct {
+	Options []args.GetArgsOption
+	Cases   []Case
+}
+
+// Core contains the cases that every dialect is expected to split in the same way
+var Core = []Case{
+	{"empty", "", []string{}},
+	{"spaces", "  \t ", []string{}},
+	{"words", "one two  three", []string{"one", "two", "three"}},
+	{"leading and trailing spaces", "  one two  ", []string{"one", "two"}},
+	{"double quotes", `one "two three"`, []string{"one", "two three"}},
+	{"empty quotes", `one "" two`, []string{"one", "", "two"}},
+	{"unicode", "héllo wörld", []string{"héllo", "wörld"}},
+}
+
+// SingleQuotes contains the cases for the dialects that recognize single quotes
+var SingleQuotes = []Case{
+	{"single quotes", `one 'two three'`, []string{"one", "two three"}},
+}
+
+// Escapes contains the cases for the dialects that use backslash escapes
+var Escapes = []Case{
+	{"escaped space", `one\ two three`, []string{"one two", "three"}},
+	{"escaped double quote", `"a \"b\" c"`, []string{`a "b" c`}},
+}
+
+// dialectCases are the cases specific to the predefined dialects
+var dialectCases = map[string][]Case{
+	"default": cases(SingleQuotes, Escapes, []Case{
+		{"brackets", `{"a": 1} [1, 2]`, []string{`{"a": 1}`, "[1, 2]"}},
+		{"symbols", "one |two", []string{"one", "|", "two"}},
+		{"infield quotes", `a"b c"`, []string{`a"b`, `c"`}},
+	}),
+	"posix": cases(SingleQuotes, Escapes, []Case{
+		{"literal single quotes", `'a\b'`, []string{`a\b`}},
+		{"concat", `a"b c"'d'`, []string{"ab cd"}},
+		{"symbols", "a | b", []string{"a", "|", "b"}},
+	}),
+	"bash": cases(SingleQuotes, Escapes, []Case{
+		{"literal single quotes", `'a\b'`, []string{`a\b`}},
+		{"operators", "a && b", []string{"a", "&&", "b"}},
+	}),
+	"windows": {
+		{"no single quotes", `one 'two three'`, []string{"one", "'two", "three'"}},
+		{"no escapes", `copy C:\dir\file "D:\my dir"`, []string{"copy", `C:\dir\file`, `D:\my dir`}},
+	},
+	"powershell": cases(SingleQuotes, []Case{
+		{"escaped space", "one` two three", []string{"one two", "three"}},
+		{"escaped double quote", "\"a `\"b`\" c\"", []string{`a "b" c`}},
+		{"literal backslash", `C:\dir\file`, []string{`C:\dir\file`}},
+	}),
+	"rc": cases(SingleQuotes, []Case{
+		{"no escapes", `one\ two`, []string{`one\`, "two"}},
+		{"concat", `a'b c'`, []string{"ab c"}},
+	}),
+	"systemd": cases(SingleQuotes, Escapes, []Case{
+		{"c escapes", `"a\tb"`, []string{"a\tb"}},
+		{"no brackets", `{a b}`, []string{"{a", "b}"}},
+		{"no symbols", "a|b", []string{"a|b"}},
+	}),
+	"make": cases(SingleQuotes, Escapes, []Case{
+		{"make escapes", "echo $$HOME", []string{"echo", "$HOME"}},
+		{"operators", "a && b", []string{"a", "&&", "b"}},
+		{"comments", "a # comment", []string{"a"}},
+	}),
+}
+
+// Dialects contains the dialects registered in package args (see args.RegisterDialect)
+// with the cases specific to them
+var Dialects = registeredDialects()
+
+func registeredDialects() map[string]Dialect {
+	dialects := map[string]Dialect{}
+
+	for _, name := range args.Dialects() {
+		option, _ := args.LookupDialect(name)
+		dialects[name] = Dialect{Options: []args.GetArgsOption{option}, Cases: dialectCases[name]}
+	}
+
+	return dialects
+}
+
+func cases(groups ...[]Case) []Case {
+	all := []Case{}
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+
+	return all
+}
+
+// Run splits each case line with the given options and reports the ones that don't match the expected arguments
+func Run(t testing.TB, cases []Case, options ...args.GetArgsOption) {
+	t.Helper()
+
+	for _, c := range cases {
+		actual := args.GetArgs(c.Line, options...)
+		if len(actual) == 0 && len(c.Expected) == 0 {
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.Expected) {
+			t.Errorf("%s: %q: expected %q got %q", c.Name, c.Line, c.Expected, actual)
+		}
+	}
+}
+
+// RunDialect runs the Core cases and the cases of the named dialect, using the dialect options
+// followed by the extra options (i.e. to verify a customization of the dialect)
+func RunDialect(t testing.TB, name string, extra ...args.GetArgsOption) {
+	t.Helper()
+
+	dialect, ok := Dialects[name]
+	if !ok {
+		// registered after the package initialization
+		option, found := args.LookupDialect(name)
+		if !found {
+			t.Fatalf("unknown dialect %q", name)
+		}
+
+		dialect = Dialect{Options: []args.GetArgsOption{option}}
+	}
+
+	options := append(append([]args.GetArgsOption{}, dialect.Options...), extra...)
+	Run(t, Core, options...)
+	Run(t, dialect.Cases, options...)
+}
@@ -0,0 +1,62 @@
+package argstest
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/gobs/args"
+)
+
+func TestDialects(test *testing.T) {
+	for name := range Dialects {
+		test.Run(name, func(test *testing.T) {
+			RunDialect(test, name)
+		})
+	}
+}
+
+func TestCustomDialect(test *testing.T) {
+	RunDialect(test, "posix", args.ASCIISpaces(), args.CEscapes())
+}
+
+func TestRegisteredDialect(test *testing.T) {
+	args.RegisterDialect("test-csv", args.UserTokens(","))
+	RunDialect(test, "test-csv")
+
+	csv, _ := args.LookupDialect("test-csv")
+	Run(test, []Case{{"user tokens", "a,b", []string{"a", ",", "b"}}}, csv)
+}
+
+func TestShell(test *testing.T) {
+	if testing.Short() {
+		test.Skip("skipping shell comparison in short mode")
+	}
+
+	lines := []string{}
+	for _, c := range cases(Core, SingleQuotes, Escapes) {
+		lines = append(lines, c.Line)
+	}
+
+	lines = append(lines, `a"b c"'d'`, `'a\b'`, `"a\\b"`, `one\ two`, `x\"y`)
+	RunShell(test, lines)
+}
+
+func TestShellSplit(test *testing.T) {
+	if _, err := exec.LookPath(SHELL); err != nil {
+		test.Skipf("%s not available: %v", SHELL, err)
+	}
+
+	for line, expected := range map[string][]string{
+		``:        {},
+		`""`:      {""},
+		`'' ""`:   {"", ""},
+		`a "" b`:  {"a", "", "b"},
+		`"a b" c`: {"a b", "c"},
+		`x ""`:    {"x", ""},
+	} {
+		if actual, err := ShellSplit(line); err != nil || !reflect.DeepEqual(actual, expected) {
+			test.Errorf("%q: expected %q got %q (%v)", line, expected, actual, err)
+		}
+	}
+}
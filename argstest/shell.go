@@ -0,0 +1,99 @@
+package argstest
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gobs/args"
+)
+
+// SHELL is the shell used by ShellSplit
+var SHELL = "/bin/sh"
+
+// Divergence is a line that the shell and package args split differently
+type Divergence struct {
+	Line  string
+	Shell []string
+	Args  []string
+}
+
+// ShellSplit returns the arguments as split by the system shell, running `printf '%s\0' X <line>`
+// (the X sentinel distinguishes a single empty argument from no arguments).
+// Note that the shell also performs expansions (variables, globs, command substitution),
+// so only use lines from trusted sources.
+func ShellSplit(line string) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(SHELL, "-c", `printf '%s\0' X `+line)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+
+	out := stdout.String()
+	if !strings.HasPrefix(out, "X\x00") {
+		return nil, fmt.Errorf("unexpected shell output %q", out)
+	}
+
+	out = out[2:]
+	if out == "" {
+		return []string{}, nil
+	}
+
+	// each argument is terminated by a NUL
+	return strings.Split(strings.TrimSuffix(out, "\x00"), "\x00"), nil
+}
+
+// CompareShell splits each line with the shell and with args.GetArgs (using POSIX quoting and concatenation,
+// followed by the given options) and returns the lines that are split differently.
+func CompareShell(lines []string, options ...args.GetArgsOption) ([]Divergence, error) {
+	options = append([]args.GetArgsOption{args.POSIXQuotes(), args.Concat()}, options...)
+
+	var diffs []Divergence
+
+	for _, line := range lines {
+		shell, err := ShellSplit(line)
+		if err != nil {
+			return diffs, err
+		}
+
+		actual := args.GetArgs(line, options...)
+		if len(shell) == 0 && len(actual) == 0 {
+			continue
+		}
+
+		if !reflect.DeepEqual(shell, actual) {
+			diffs = append(diffs, Divergence{Line: line, Shell: shell, Args: actual})
+		}
+	}
+
+	return diffs, nil
+}
+
+// RunShell is an opt-in test helper that reports the lines split differently by the system shell and package args.
+// The test is skipped when the shell is not available.
+func RunShell(t testing.TB, lines []string, options ...args.GetArgsOption) {
+	t.Helper()
+
+	if _, err := exec.LookPath(SHELL); err != nil {
+		t.Skipf("%s not available: %v", SHELL, err)
+	}
+
+	diffs, err := CompareShell(lines, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range diffs {
+		t.Errorf("%q: shell %q, args %q", d.Line, d.Shell, d.Args)
+	}
+}
@@ -0,0 +1,44 @@
+package args
+
+// Builder accumulates the arguments of a command, as an alternative to building command lines
+// by string concatenation:
+//
+//	cmd := args.New("rsync").Flag("-av").Opt("--exclude", pattern).Arg(src).Arg(dst)
+//	argv := cmd.Argv() // or cmd.String() for a quoted command line
+//	exec.Command(argv[0], argv[1:]...)
+type Builder struct {
+	argv []string
+}
+
+// New returns a Builder for the named command
+func New(name string) *Builder {
+	return &Builder{argv: []string{name}}
+}
+
+// Flag adds one or more flags (i.e. "-v", "--verbose")
+func (b *Builder) Flag(flags ...string) *Builder {
+	b.argv = append(b.argv, flags...)
+	return b
+}
+
+// Opt adds an option followed by its value, as separate arguments (i.e. "--exclude", "*.tmp")
+func (b *Builder) Opt(name, value string) *Builder {
+	b.argv = append(b.argv, name, value)
+	return b
+}
+
+// Arg adds one or more positional arguments
+func (b *Builder) Arg(args ...string) *Builder {
+	b.argv = append(b.argv, args...)
+	return b
+}
+
+// Argv returns the command and its arguments
+func (b *Builder) Argv() []string {
+	return append([]string{}, b.argv...)
+}
+
+// String returns the command line, with the arguments quoted as needed (see Join)
+func (b *Builder) String() string {
+	return Join(b.argv)
+}
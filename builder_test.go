@@ -0,0 +1,32 @@
+package args
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestBuilder(test *testing.T) {
+	cmd := New("rsync").Flag("-a", "-v").Opt("--exclude", "*.tmp").Arg("my dir/").Arg("host:backup")
+
+	expected := []string{"rsync", "-a", "-v", "--exclude", "*.tmp", "my dir/", "host:backup"}
+	if argv := cmd.Argv(); !reflect.DeepEqual(argv, expected) {
+		test.Errorf("expected %q got %q", expected, argv)
+	}
+
+	if args := GetArgs(cmd.String()); !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+
+	argv := cmd.Argv()
+	argv[0] = "changed"
+	if cmd.Argv()[0] != "rsync" {
+		test.Errorf("Argv should return a copy")
+	}
+}
+
+func ExampleBuilder() {
+	fmt.Println(New("grep").Flag("-r").Opt("-e", "hello world").Arg("."))
+	// Output:
+	// grep -r -e "hello world" .
+}
@@ -0,0 +1,59 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+// CACHE_VERSION is the version of the token cache format, incremented when Token changes
+const CACHE_VERSION = 1
+
+type cacheHeader struct {
+	Version int
+	Count   int
+}
+
+// EncodeTokens writes a list of tokens (see Scanner.Tokens) to w in binary (gob) format,
+// so that the result of parsing a large script can be cached and reloaded with DecodeTokens.
+func EncodeTokens(w io.Writer, tokens []Token) error {
+	enc := gob.NewEncoder(w)
+
+	if err := enc.Encode(cacheHeader{Version: CACHE_VERSION, Count: len(tokens)}); err != nil {
+		return err
+	}
+
+	return enc.Encode(tokens)
+}
+
+// DecodeTokens reads a list of tokens written by EncodeTokens.
+// It returns ErrInvalidCache if the data was written with a different version of the format.
+func DecodeTokens(r io.Reader) ([]Token, error) {
+	dec := gob.NewDecoder(r)
+
+	var header cacheHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCache, err)
+	}
+
+	if header.Version != CACHE_VERSION {
+		return nil, fmt.Errorf("%w: version %d, expected %d", ErrInvalidCache, header.Version, CACHE_VERSION)
+	}
+
+	tokens := []Token{}
+	if header.Count == 0 {
+		return tokens, nil
+	}
+
+	if err := dec.Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCache, err)
+	}
+
+	if len(tokens) != header.Count {
+		return nil, fmt.Errorf("%w: expected %d tokens, got %d", ErrInvalidCache, header.Count, len(tokens))
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,49 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTokenCache(test *testing.T) {
+	scanner := NewScannerString("# build\nCC=gcc make -j4 'all targets' | tee log\n")
+	scanner.Comments = WordStartComments
+	scanner.CommentTokens = true
+	scanner.TrackSegments = true
+
+	tokens, err := scanner.Tokens()
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeTokens(&buf, tokens); err != nil {
+		test.Fatal(err)
+	}
+
+	decoded, err := DecodeTokens(&buf)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded, tokens) {
+		test.Errorf("expected %+v got %+v", tokens, decoded)
+	}
+
+	buf.Reset()
+	EncodeTokens(&buf, nil)
+	if decoded, err := DecodeTokens(&buf); err != nil || len(decoded) != 0 {
+		test.Errorf("expected no tokens, got %v %v", decoded, err)
+	}
+
+	buf.Reset()
+	gob.NewEncoder(&buf).Encode(cacheHeader{Version: CACHE_VERSION + 1})
+	if _, err := DecodeTokens(&buf); !errors.Is(err, ErrInvalidCache) {
+		test.Errorf("expected ErrInvalidCache, got %v", err)
+	}
+}
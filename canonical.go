@@ -0,0 +1,87 @@
+package args
+
+import (
+	"sort"
+	"strings"
+)
+
+// Canonicalizer normalizes command lines, so that equivalent command lines have the same representation
+type Canonicalizer struct {
+	SortOptions  bool            // sort consecutive options by name
+	ValueOptions []string        // options that take the value in the next argument (--key v is normalized as --key=v)
+	Options      []GetArgsOption // options used to split the command line
+}
+
+// Canonicalize normalizes a command line: quoting is normalized (see Quote), spaces are collapsed
+// and consecutive options are sorted by name. Options keep their original prefix (-name is not --name).
+func Canonicalize(line string) string {
+	c := Canonicalizer{SortOptions: true}
+	return c.Canonicalize(line)
+}
+
+// Canonicalize normalizes a command line (see Canonicalize)
+func (c Canonicalizer) Canonicalize(line string) string {
+	args := GetArgs(line, c.Options...)
+
+	values := map[string]bool{}
+	for _, name := range c.ValueOptions {
+		values[strings.TrimLeft(name, "-")] = true
+	}
+
+	out := []string{}
+	opts := [][]string{} // current run of options (with their separate value, if any)
+
+	flush := func() {
+		if c.SortOptions {
+			sort.SliceStable(opts, func(i, j int) bool {
+				return optionName(opts[i][0]) < optionName(opts[j][0])
+			})
+		}
+
+		for _, opt := range opts {
+			out = append(out, opt...)
+		}
+		opts = opts[:0]
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			flush()
+			out = append(out, args[i:]...)
+			break
+		}
+
+		if len(arg) < 2 || arg[0] != '-' {
+			flush()
+			out = append(out, arg)
+			continue
+		}
+
+		name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+
+		switch {
+		case !values[name] || strings.Contains(arg, "=") || i+1 == len(args):
+			opts = append(opts, []string{arg})
+
+		case strings.HasPrefix(arg, "--"):
+			// --name value is the same as --name=value
+			opts = append(opts, []string{arg + "=" + args[i+1]})
+			i++
+
+		default:
+			// -n value can't be joined (-n=value would be a different value)
+			opts = append(opts, []string{arg, args[i+1]})
+			i++
+		}
+	}
+
+	flush()
+	return Join(out)
+}
+
+// optionName returns the name of the option (without dashes and value)
+func optionName(opt string) string {
+	return strings.SplitN(strings.TrimLeft(opt, "-"), "=", 2)[0]
+}
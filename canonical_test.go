@@ -0,0 +1,29 @@
+package args
+
+import (
+	"testing"
+)
+
+func TestCanonicalize(test *testing.T) {
+	for line, expected := range map[string]string{
+		`git   --verbose  commit -m 'fix it'  -a`: `git --verbose commit -m "fix it" -a`,
+		`cmd --b=2 -a --c --a=1 x -- --z 'y z'`:   `cmd -a --a=1 --b=2 --c x -- --z "y z"`,
+		`cmd ---name=joe -vx "" '$HOME'`:          `cmd ---name=joe -vx "" "\$HOME"`,
+		`find . -name '*.go' -type f`:             `find . -name "*.go" -type f`,
+		`tar -xvf a.tar`:                          `tar -xvf a.tar`,
+		`cmd "--label=my label"`:                  `cmd "--label=my label"`,
+	} {
+		if c := Canonicalize(line); c != expected {
+			test.Errorf("%v: expected %v got %v", line, expected, c)
+		}
+	}
+
+	c := Canonicalizer{ValueOptions: []string{"-o", "name"}}
+	if s, expected := c.Canonicalize(`cc -o out.c --name 'a b' -x file`), `cc -o out.c "--name=a b" -x file`; s != expected {
+		test.Errorf("expected %v got %v", expected, s)
+	}
+
+	if Canonicalize(`ls  -l  "my dir"`) != Canonicalize(`ls -l my\ dir`) {
+		test.Errorf("expected equivalent command lines to have the same canonical form")
+	}
+}
@@ -0,0 +1,63 @@
+package args
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ChatCommand is a command parsed from a chat message (see ParseChatCommand)
+type ChatCommand struct {
+	Name     string   // command name, without prefix
+	Mentions []string // leading mentions, without the @
+	Args     Args     // parsed arguments
+	Rest     string   // raw text after the command name
+}
+
+// ParseChatCommand parses a chat message in the form "[@mention...] <prefix>command arguments...",
+// where prefix is one of the characters in prefixes (i.e. "!/").
+// The prefix is optional if the message starts with a mention (i.e. "@bot help").
+// Returns false if the message is not a command.
+func ParseChatCommand(line, prefixes string, options ...GetArgsOption) (cmd ChatCommand, ok bool) {
+	line = strings.TrimSpace(line)
+
+	//
+	// leading mentions (@name, @name: or @name,)
+	//
+	for strings.HasPrefix(line, "@") {
+		end := strings.IndexFunc(line, unicode.IsSpace)
+		if end < 0 {
+			end = len(line)
+		}
+
+		if mention := strings.TrimRight(line[1:end], ":,"); mention != "" {
+			cmd.Mentions = append(cmd.Mentions, mention)
+		}
+
+		line = strings.TrimLeftFunc(line[end:], unicode.IsSpace)
+	}
+
+	if line == "" {
+		return cmd, false
+	}
+
+	if c, size := utf8.DecodeRuneInString(line); strings.ContainsRune(prefixes, c) {
+		line = line[size:]
+	} else if len(cmd.Mentions) == 0 {
+		return cmd, false
+	}
+
+	end := strings.IndexFunc(line, unicode.IsSpace)
+	if end < 0 {
+		end = len(line)
+	}
+
+	cmd.Name = line[:end]
+	if cmd.Name == "" {
+		return cmd, false
+	}
+
+	cmd.Rest = strings.TrimLeftFunc(line[end:], unicode.IsSpace)
+	cmd.Args = ParseArgs(cmd.Rest, options...)
+	return cmd, true
+}
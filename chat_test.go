@@ -0,0 +1,28 @@
+package args
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseChatCommand(test *testing.T) {
+	cmd, ok := ParseChatCommand(`@bot: @other !remind --in=5m "stand up" now`, "!/")
+	if !ok {
+		test.Fatal("not a command")
+	}
+
+	if fmt.Sprintf("%q %q %q %q %q", cmd.Name, cmd.Mentions, cmd.Args.Options, cmd.Args.Arguments, cmd.Rest) !=
+		`"remind" ["bot" "other"] map["in":"5m"] ["stand up" "now"] "--in=5m \"stand up\" now"` {
+		test.Errorf("unexpected command %#v", cmd)
+	}
+
+	if cmd, ok := ParseChatCommand("@bot help me", "!"); !ok || cmd.Name != "help" || cmd.Rest != "me" {
+		test.Errorf("unexpected command %#v", cmd)
+	}
+
+	for _, line := range []string{"just chatting", "!", "@bot", ""} {
+		if cmd, ok := ParseChatCommand(line, "!/"); ok {
+			test.Errorf("%q: unexpected command %#v", line, cmd)
+		}
+	}
+}
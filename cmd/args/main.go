@@ -0,0 +1,165 @@
+// Command args splits the lines read from the files (or the standard input) into arguments, using package args,
+// and prints them one per line (or NUL-separated, or as JSON arrays).
+//
+// Usage:
+//
+//	args [-dialect name] [-n count] [-0 | -json] [-strict] [file ...]
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gobs/args"
+)
+
+type config struct {
+	options []args.GetArgsOption
+	n       int  // split in at most n arguments
+	nul     bool // NUL-separated output
+	json    bool // JSON output (an array per line)
+	strict  bool // report malformed lines
+}
+
+// split returns the arguments for one input line (and the parsing error, in strict mode)
+func (c *config) split(line string) ([]string, error) {
+	scanner := args.NewScannerString(line)
+	for _, option := range c.options {
+		option(scanner)
+	}
+
+	scanner.Strict = c.strict
+
+	n := 0
+	if c.n > 0 {
+		n = c.n - 1 // the last one is the remainder
+	}
+
+	list, rest, err := scanner.GetTokensN(n)
+	if err == io.EOF {
+		err = nil
+	}
+	if err != nil && c.strict {
+		return nil, err
+	}
+
+	if rest != "" {
+		list = append(list, rest)
+	}
+
+	return list, nil
+}
+
+// run splits each line from in and writes the arguments to out.
+// Malformed lines (in strict mode) are reported to errs and skipped.
+func (c *config) run(in io.Reader, out, errs io.Writer) (ok bool, err error) {
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	ok = true
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1024*1024)
+
+	for lineno := 1; scanner.Scan(); lineno++ {
+		list, err := c.split(scanner.Text())
+		if err != nil {
+			fmt.Fprintf(errs, "line %d: %v\n", lineno, err)
+			ok = false
+			continue
+		}
+
+		switch {
+		case c.json:
+			b, _ := json.Marshal(list)
+			w.Write(b)
+			w.WriteByte('\n')
+
+		case c.nul:
+			for _, arg := range list {
+				w.WriteString(arg)
+				w.WriteByte(0)
+			}
+
+		default:
+			for _, arg := range list {
+				w.WriteString(arg)
+				w.WriteByte('\n')
+			}
+		}
+	}
+
+	return ok, scanner.Err()
+}
+
+// runFile runs the named file ("-" for the standard input), reporting the malformed lines with the file name
+func (c *config) runFile(name string, out, errs io.Writer) (bool, error) {
+	if name == "-" {
+		return c.run(os.Stdin, out, errs)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	return c.run(f, out, &prefixWriter{prefix: name + ": ", w: errs})
+}
+
+// prefixWriter writes each (line) write to w with a prefix
+type prefixWriter struct {
+	prefix string
+	w      io.Writer
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	if _, err := io.WriteString(p.w, p.prefix); err != nil {
+		return 0, err
+	}
+
+	return p.w.Write(b)
+}
+
+func main() {
+	dialect := flag.String("dialect", "default", "parsing dialect ("+strings.Join(args.Dialects(), ", ")+")")
+	n := flag.Int("n", 0, "split each line in at most n arguments (the last one is the unsplit remainder)")
+	nul := flag.Bool("0", false, "terminate each argument with a NUL character instead of a newline")
+	jsonOut := flag.Bool("json", false, "print a JSON array of arguments for each line")
+	strict := flag.Bool("strict", false, "report malformed lines (unterminated quotes, unbalanced brackets, ...), except in the -n remainder")
+	flag.Parse()
+
+	option, found := args.LookupDialect(*dialect)
+	if !found {
+		fmt.Fprintf(os.Stderr, "unknown dialect %q\n", *dialect)
+		os.Exit(2)
+	}
+
+	c := config{options: []args.GetArgsOption{option}, n: *n, nul: *nul, json: *jsonOut, strict: *strict}
+
+	names := flag.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	ok := true
+
+	// each file is processed separately, so that a last line without a newline is not joined to the next file
+	for _, name := range names {
+		fileOk, err := c.runFile(name, os.Stdout, os.Stderr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		ok = ok && fileOk
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+}
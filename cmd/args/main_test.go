@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gobs/args"
+)
+
+func TestRun(test *testing.T) {
+	input := "echo \"hello world\" x\nls -l 'my dir'\n"
+
+	tests := []struct {
+		config   config
+		expected string
+	}{
+		{config{}, "echo\nhello world\nx\nls\n-l\nmy dir\n"},
+		{config{nul: true}, "echo\x00hello world\x00x\x00ls\x00-l\x00my dir\x00"},
+		{config{json: true}, "[\"echo\",\"hello world\",\"x\"]\n[\"ls\",\"-l\",\"my dir\"]\n"},
+		{config{n: 2, json: true}, "[\"echo\",\"\\\"hello world\\\" x\"]\n[\"ls\",\"-l 'my dir'\"]\n"},
+	}
+
+	for _, t := range tests {
+		var out, errs strings.Builder
+
+		if ok, err := t.config.run(strings.NewReader(input), &out, &errs); !ok || err != nil {
+			test.Errorf("unexpected failure %v %v", err, errs.String())
+		}
+
+		if out.String() != t.expected {
+			test.Errorf("expected %q got %q", t.expected, out.String())
+		}
+	}
+}
+
+func TestRunStrict(test *testing.T) {
+	posix, _ := args.LookupDialect("posix")
+	c := config{options: []args.GetArgsOption{posix}, strict: true}
+
+	var out, errs strings.Builder
+
+	ok, err := c.run(strings.NewReader("echo 'a\\b'\necho \"open\nok\n"), &out, &errs)
+	if ok || err != nil {
+		test.Errorf("expected a malformed line, got %v %v", ok, err)
+	}
+
+	if expected := "echo\na\\b\nok\n"; out.String() != expected {
+		test.Errorf("expected %q got %q", expected, out.String())
+	}
+
+	if !strings.HasPrefix(errs.String(), "line 2: ") {
+		test.Errorf("unexpected errors %q", errs.String())
+	}
+}
+
+func TestRunStrictN(test *testing.T) {
+	c := config{n: 2, strict: true}
+
+	var out, errs strings.Builder
+
+	if ok, _ := c.run(strings.NewReader("a b c\n\"open b\na \"rest\n"), &out, &errs); ok {
+		test.Errorf("expected a malformed line")
+	}
+
+	// the remainder is not split, nor validated
+	if expected := "a\nb c\na\n\"rest\n"; out.String() != expected {
+		test.Errorf("expected %q got %q", expected, out.String())
+	}
+
+	if !strings.HasPrefix(errs.String(), "line 2: ") || strings.Count(errs.String(), "\n") != 1 {
+		test.Errorf("unexpected errors %q", errs.String())
+	}
+}
+
+func TestRunFiles(test *testing.T) {
+	dir := test.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+
+	os.WriteFile(first, []byte("a b\nc \"d"), 0644) // no trailing newline
+	os.WriteFile(second, []byte("e\" f\n"), 0644)
+
+	c := config{json: true, strict: true}
+
+	var out, errs strings.Builder
+
+	for _, name := range []string{first, second} {
+		if _, err := c.runFile(name, &out, &errs); err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	if expected := "[\"a\",\"b\"]\n[\"e\\\"\",\"f\"]\n"; out.String() != expected {
+		test.Errorf("expected %q got %q", expected, out.String())
+	}
+
+	if expected := first + ": line 2: "; !strings.HasPrefix(errs.String(), expected) || strings.Count(errs.String(), "\n") != 1 {
+		test.Errorf("unexpected errors %q", errs.String())
+	}
+}
@@ -0,0 +1,119 @@
+package args
+
+import (
+	"io"
+	"strings"
+)
+
+// TokenAt returns the index of the token containing the cursor (a byte offset in line), the token
+// and the (unquoted) part of the token before the cursor, as needed for completion.
+//
+// If the cursor is not inside a token (i.e. it's on a separator or at the end of the line after a separator)
+// TokenAt returns the index of the next token, an empty token and an empty prefix.
+// Token indexes are the same as for IndexToken and ReplaceToken (user token delimiters count as tokens).
+func TokenAt(line string, cursor int, options ...GetArgsOption) (index int, tok Token, prefix string) {
+	if cursor < 0 {
+		cursor = 0
+	} else if cursor > len(line) {
+		cursor = len(line)
+	}
+
+	index = -1
+
+	scanTokens(line, options, func(i int, t Token) bool {
+		index = i
+		if len(t.Segments) == 0 {
+			return true
+		}
+
+		start := t.Segments[0].Start
+		end := t.Segments[len(t.Segments)-1].End
+
+		if cursor < start {
+			return false
+		}
+
+		// a delimiter only contains the cursor if it's right before it
+		if cursor < end || (cursor == end && t.End != EndUserToken) {
+			tok, prefix = t, tokenPrefix(line[start:cursor], options...)
+			return false
+		}
+
+		index++
+		return true
+	})
+
+	if index < 0 {
+		index = 0
+	}
+
+	return index, tok, prefix
+}
+
+// tokenPrefix returns the value of the (possibly incomplete) token in text
+func tokenPrefix(text string, options ...GetArgsOption) string {
+	scanner := getScanner(text, options...)
+	scanner.Strict = false
+
+	tok, err := scanner.Next()
+	if err != nil && err != io.EOF && tok.Value == "" {
+		return ""
+	}
+
+	return tok.Value
+}
+
+// Complete completes the option or option value under the cursor (see TokenAt), for the declared options.
+// An option value (--name=partial) is completed with the option Complete function or Choices,
+// an option name (--partial) with the names of the declared options (that are not hidden).
+// It returns the byte range in line to replace and the candidate replacements (none if the cursor
+// is not on an option: the caller can try other completions, i.e. CompletePath).
+func (s *Spec) Complete(line string, cursor int, options ...GetArgsOption) (start, end int, candidates []string) {
+	_, tok, prefix := TokenAt(line, cursor, options...)
+	if len(tok.Segments) == 0 || !strings.HasPrefix(prefix, "-") {
+		return cursor, cursor, nil
+	}
+
+	start = tok.Segments[0].Start
+	end = tok.Segments[len(tok.Segments)-1].End
+
+	if eq := strings.IndexByte(prefix, '='); eq >= 0 {
+		flag, partial := prefix[:eq+1], prefix[eq+1:]
+		name := strings.TrimLeft(flag[:eq], "-")
+
+		for _, o := range s.Options {
+			if o.Name != name {
+				continue
+			}
+
+			values := o.Choices
+			if o.Complete != nil {
+				values = o.Complete(partial)
+			}
+
+			for _, v := range values {
+				if strings.HasPrefix(v, partial) {
+					candidates = append(candidates, flag+escapeSpecial(v))
+				}
+			}
+		}
+
+		return start, end, candidates
+	}
+
+	partial := strings.TrimLeft(prefix, "-")
+
+	for _, o := range s.Options {
+		if o.Hidden || !strings.HasPrefix(o.Name, partial) {
+			continue
+		}
+
+		if o.Type == BoolOption {
+			candidates = append(candidates, optionFlag(o.Name))
+		} else {
+			candidates = append(candidates, optionFlag(o.Name)+"=")
+		}
+	}
+
+	return start, end, candidates
+}
@@ -0,0 +1,71 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CompletePath completes the token under the cursor (see TokenAt) as a file path.
+// It returns the byte range in line to replace (the whole token, or the cursor position if not inside a token)
+// and the candidate replacements, quoted as the original token (see PathCompletions).
+func CompletePath(line string, cursor int, options ...GetArgsOption) (start, end int, candidates []string) {
+	_, tok, prefix := TokenAt(line, cursor, options...)
+
+	if len(tok.Segments) == 0 {
+		if cursor < 0 {
+			cursor = 0
+		} else if cursor > len(line) {
+			cursor = len(line)
+		}
+
+		return cursor, cursor, PathCompletions("", NO_QUOTE)
+	}
+
+	start = tok.Segments[0].Start
+	end = tok.Segments[len(tok.Segments)-1].End
+	return start, end, PathCompletions(prefix, tok.Segments[0].Quote)
+}
+
+// PathCompletions returns the file system entries that start with prefix (an unquoted partial path),
+// ready to be inserted in a command line: quoted with the specified quote character (' or "),
+// or with special characters escaped if quote is NO_QUOTE. Directories end with a "/".
+// Hidden files are only returned if the prefix of the name starts with ".".
+func PathCompletions(prefix string, quote rune) []string {
+	dir, base := filepath.Split(prefix)
+
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	}
+
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+
+	candidates := []string{}
+	settings := getScanner("") // default quoting rules
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+
+		path := dir + name
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			path += "/"
+		}
+
+		if quote == NO_QUOTE {
+			candidates = append(candidates, escapeSpecial(path))
+		} else {
+			candidates = append(candidates, quoteLike(path, quote, settings))
+		}
+	}
+
+	return candidates
+}
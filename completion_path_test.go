@@ -0,0 +1,63 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPathCompletions(test *testing.T) {
+	dir := test.TempDir()
+
+	for _, name := range []string{"my file.txt", "it's", ".hidden", "other"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			test.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "my dir"), 0755); err != nil {
+		test.Fatal(err)
+	}
+
+	dir += "/"
+
+	tests := []struct {
+		prefix   string
+		quote    rune
+		expected []string
+	}{
+		{dir + "my", NO_QUOTE, []string{dir + `my\ dir/`, dir + `my\ file.txt`}},
+		{dir + "my f", '"', []string{`"` + dir + `my file.txt"`}},
+		{dir + "it", '\'', []string{`"` + dir + `it's"`}},
+		{dir + ".", NO_QUOTE, []string{dir + ".hidden"}},
+		{dir + "o", NO_QUOTE, []string{dir + "other"}},
+		{dir + "x", NO_QUOTE, []string{}},
+	}
+
+	for _, t := range tests {
+		candidates := PathCompletions(t.prefix, t.quote)
+		if !reflect.DeepEqual(candidates, t.expected) {
+			test.Errorf("%q: expected %q got %q", t.prefix, t.expected, candidates)
+		}
+
+		// the candidates are parsed back as the original path
+		for _, c := range candidates {
+			if args := GetArgs(c); len(args) != 1 || !strings.HasPrefix(args[0], t.prefix) {
+				test.Errorf("%q: unexpected parsing %q", c, args)
+			}
+		}
+	}
+
+	line := `cat "` + dir + `my f`
+	start, end, candidates := CompletePath(line, len(line))
+	if expected := []string{`"` + dir + `my file.txt"`}; start != 4 || end != len(line) || !reflect.DeepEqual(candidates, expected) {
+		test.Errorf("expected 4 %v %q got %v %v %q", len(line), expected, start, end, candidates)
+	}
+
+	if replaced := line[:start] + candidates[0] + line[end:]; !reflect.DeepEqual(GetArgs(replaced), []string{"cat", dir + "my file.txt"}) {
+		test.Errorf("unexpected completed line %q", replaced)
+	}
+}
@@ -0,0 +1,88 @@
+package args
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTokenAt(test *testing.T) {
+	line := `git commit -m "fix the bug" --amend `
+
+	tests := []struct {
+		cursor int
+		index  int
+		value  string
+		prefix string
+	}{
+		{0, 0, "git", ""},
+		{2, 0, "git", "gi"},
+		{3, 0, "git", "git"},
+		{8, 1, "commit", "comm"},
+		{15, 3, "fix the bug", ""},
+		{22, 3, "fix the bug", "fix the"},
+		{27, 3, "fix the bug", "fix the bug"},
+		{30, 4, "--amend", "--"},
+		{36, 5, "", ""},
+		{100, 5, "", ""},
+	}
+
+	for _, t := range tests {
+		index, tok, prefix := TokenAt(line, t.cursor)
+		if index != t.index || tok.Value != t.value || prefix != t.prefix {
+			test.Errorf("cursor %v: expected %v %q %q got %v %q %q", t.cursor, t.index, t.value, t.prefix, index, tok.Value, prefix)
+		}
+	}
+
+	// unterminated quote
+	if index, tok, prefix := TokenAt(`cat 'my fi`, 10); index != 1 || tok.Value != "my fi" || prefix != "my fi" {
+		test.Errorf("expected 1 %q %q got %v %q %q", "my fi", "my fi", index, tok.Value, prefix)
+	}
+
+	// between tokens
+	if index, tok, prefix := TokenAt(`a  b`, 2); index != 1 || tok.Value != "" || prefix != "" {
+		test.Errorf("expected 1 %q %q got %v %q %q", "", "", index, tok.Value, prefix)
+	}
+}
+
+func ExampleTokenAt() {
+	line := `ls --color=auto "my doc`
+	index, tok, prefix := TokenAt(line, 19)
+	fmt.Printf("%v %q %q\n", index, tok.Value, prefix)
+	// Output:
+	// 2 "my doc" "my"
+}
+
+func TestSpecComplete(test *testing.T) {
+	spec := Spec{
+		Name: "render",
+		Options: []OptionSpec{
+			{Name: "format", Choices: []string{"png", "pdf", "svg"}},
+			{Name: "font", Complete: func(prefix string) []string {
+				return []string{"Sans Serif", "Serif", "Mono"}
+			}},
+			{Name: "force", Type: BoolOption},
+			{Name: "debug", Type: BoolOption, Hidden: true},
+		},
+	}
+
+	tests := []struct {
+		line       string
+		start      int
+		candidates []string
+	}{
+		{"render --format=p", 7, []string{"--format=png", "--format=pdf"}},
+		{"render --format=", 7, []string{"--format=png", "--format=pdf", "--format=svg"}},
+		{"render --font=S", 7, []string{`--font=Sans\ Serif`, "--font=Serif"}},
+		{"render --fo", 7, []string{"--format=", "--font=", "--force"}},
+		{"render --d", 7, nil},
+		{"render fi", 9, nil},
+	}
+
+	for _, t := range tests {
+		start, end, candidates := spec.Complete(t.line, len(t.line))
+		if start != t.start || end != len(t.line) || !reflect.DeepEqual(candidates, t.candidates) {
+			test.Errorf("%q: expected %v %q got %v %v %q", t.line, t.start, t.candidates, start, end, candidates)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package args
+
+import (
+	"io"
+	"strings"
+	"unicode"
+)
+
+// CronEntry is a command parsed from a crontab line
+type CronEntry struct {
+	Schedule string   // the time fields (i.e. "*/5 * * * *" or "@reboot")
+	Args     []string // the command arguments
+	Stdin    string   // data sent to the command standard input (from the % separated text)
+}
+
+// ParseCrontabLine parses a crontab line: it skips the five time fields (or the @reboot/@daily/... nickname),
+// handles % as the cron newline separator (the first % starts the standard input data, \% is a literal %)
+// and splits the command, using POSIX shell quoting rules.
+func ParseCrontabLine(line string) (entry CronEntry, err error) {
+	line = strings.TrimSpace(line)
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return entry, wrapDetail(ErrInvalidCrontab, "no command")
+	}
+
+	nfields := 5
+	if strings.HasPrefix(line, "@") {
+		nfields = 1
+	}
+
+	rest := line
+	for i := 0; i < nfields; i++ {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			return entry, wrapDetail(ErrInvalidCrontab, "missing command")
+		}
+
+		rest = rest[end:]
+	}
+
+	entry.Schedule = strings.TrimSpace(line[:len(line)-len(rest)])
+
+	//
+	// split command and standard input on unescaped %
+	//
+	var cmd, stdin strings.Builder
+	out := &cmd
+
+	for i := 0; i < len(rest); i++ {
+		switch {
+		case rest[i] == '\\' && i+1 < len(rest) && rest[i+1] == '%':
+			out.WriteByte('%')
+			i++
+
+		case rest[i] == '%' && out == &cmd:
+			out = &stdin
+
+		case rest[i] == '%':
+			out.WriteByte('\n')
+
+		default:
+			out.WriteByte(rest[i])
+		}
+	}
+
+	entry.Stdin = stdin.String()
+
+	scanner := getScanner(cmd.String(), Concat(), POSIXQuotes(), ShellOperators(), Comments(WordStartComments), Strict())
+	entry.Args = []string{}
+
+	for {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return entry, err
+		}
+
+		entry.Args = append(entry.Args, tok.Value)
+	}
+
+	if len(entry.Args) == 0 {
+		return entry, wrapDetail(ErrInvalidCrontab, "missing command")
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,30 @@
+package args
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseCrontabLine(test *testing.T) {
+	for line, expected := range map[string]CronEntry{
+		`*/5 * * * 1-5 /usr/bin/backup --dest "/mnt/my disk"`:  {"*/5 * * * 1-5", []string{"/usr/bin/backup", "--dest", "/mnt/my disk"}, ""},
+		`@reboot  mail -s 'rebooted' root%the system%rebooted`: {"@reboot", []string{"mail", "-s", "rebooted", "root"}, "the system\nrebooted"},
+		`0 0 1 * * date +\%Y-\%m`:                              {"0 0 1 * *", []string{"date", "+%Y-%m"}, ""},
+	} {
+		entry, err := ParseCrontabLine(line)
+		if err != nil {
+			test.Error(err)
+		}
+
+		if !reflect.DeepEqual(entry, expected) {
+			test.Errorf("%v: expected %q got %q", line, expected, entry)
+		}
+	}
+
+	for _, line := range []string{"# comment", "* * * *", "@daily", ""} {
+		if _, err := ParseCrontabLine(line); !errors.Is(err, ErrInvalidCrontab) {
+			test.Errorf("%q: expected ErrInvalidCrontab, got %v", line, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package args
+
+import (
+	"errors"
+	"io"
+	"strconv"
+)
+
+// Severity is the severity of a Diagnostic
+type Severity int
+
+const (
+	SeverityError   Severity = iota // malformed input
+	SeverityWarning                 // suspicious input
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+
+	return "Severity(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Diagnostic describes a problem found in the input
+type Diagnostic struct {
+	Offset   int // byte offset of the problem in the input
+	Severity Severity
+	Message  string
+	Err      error // the error reported (see ParseError)
+}
+
+func (d Diagnostic) String() string {
+	return strconv.Itoa(d.Offset) + ": " + d.Severity.String() + ": " + d.Message
+}
+
+// report records a problem found while scanning
+func (scanner *Scanner) report(severity Severity, err error) {
+	d := Diagnostic{Offset: errorOffset(err, scanner.offset), Severity: severity, Message: err.Error(), Err: err}
+
+	if pe, ok := err.(*ParseError); ok {
+		d.Message = pe.Err.Error()
+		if pe.Detail != "" {
+			d.Message += " " + pe.Detail
+		}
+	}
+
+	scanner.diagnostics = append(scanner.diagnostics, d)
+
+	if scanner.Strict && severity == SeverityError && scanner.strictErr == nil {
+		scanner.strictErr = err
+	}
+}
+
+// reported returns true if the error was already recorded (i.e. by Next, in strict mode)
+func (scanner *Scanner) reported(err error) bool {
+	for _, d := range scanner.diagnostics {
+		if d.Err == err {
+			return true
+		}
+	}
+
+	return false
+}
+
+// strictError returns (and clears) the first error found in strict mode
+func (scanner *Scanner) strictError() error {
+	err := scanner.strictErr
+	scanner.strictErr = nil
+	return err
+}
+
+// Diagnostics returns the problems found so far by the Scanner
+func (scanner *Scanner) Diagnostics() []Diagnostic {
+	return scanner.diagnostics
+}
+
+// Validate walks the input line and returns all the problems found
+// (unterminated quotes, unbalanced brackets, trailing escape characters).
+// In strict mode it stops at the first error.
+func Validate(line string, options ...GetArgsOption) []Diagnostic {
+	scanner := getScanner(line, options...)
+
+	for {
+		if _, err := scanner.Next(); err != nil {
+			if err != io.EOF && !scanner.reported(err) {
+				scanner.report(SeverityError, err)
+			}
+			break
+		}
+	}
+
+	return scanner.Diagnostics()
+}
+
+// GetArgsLenient parses the input line into an array of arguments, recovering from errors.
+// It always returns the arguments it could recover, plus the list of problems found.
+func GetArgsLenient(line string, options ...GetArgsOption) (args []string, errs []Diagnostic) {
+	scanner := getScanner(line, options...)
+	args = []string{}
+
+	for {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if !scanner.reported(err) {
+				scanner.report(SeverityError, err)
+			}
+
+			if tok.Value != "" {
+				args = append(args, tok.Value)
+			}
+
+			if errors.Is(err, ErrInvalidUTF8) {
+				continue // the invalid sequence was skipped
+			}
+			break
+		}
+
+		args = scanner.appendToken(args, tok)
+	}
+
+	return args, scanner.Diagnostics()
+}
@@ -0,0 +1,60 @@
+package args
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(test *testing.T) {
+	for line, expected := range map[string][]Diagnostic{
+		`echo "hello world" {"a": [1, 2]}`: nil,
+		`echo "hello`:                      {{5, SeverityError, "unterminated quote", ErrUnterminatedQuote}},
+		`echo trailing\`:                   {{13, SeverityError, "trailing escape character", ErrTrailingEscape}},
+		`set {"a": [1, 2}`: {
+			{15, SeverityError, `mismatched bracket '}' (expected ']')`, ErrMismatchedBracket},
+			{4, SeverityError, "unbalanced bracket", ErrUnbalancedBracket},
+			{10, SeverityError, "unbalanced bracket", ErrUnbalancedBracket},
+		},
+	} {
+		for _, strict := range []bool{false, true} {
+			diags := Validate(line)
+			if strict {
+				// the first error only, reported once
+				diags = Validate(line, Strict())
+				if len(expected) > 1 {
+					expected = expected[:1]
+				}
+			}
+
+			if len(diags) != len(expected) {
+				test.Errorf("%v: expected %v got %v", line, expected, diags)
+				continue
+			}
+
+			for i, d := range diags {
+				exp := expected[i]
+
+				if d.Offset != exp.Offset || d.Severity != exp.Severity || d.Message != exp.Message || !errors.Is(d.Err, exp.Err) {
+					test.Errorf("%v: expected %v got %v", line, exp, d)
+				}
+			}
+		}
+	}
+}
+
+func TestGetArgsLenient(test *testing.T) {
+	args, errs := GetArgsLenient("echo b\xffd {\"a\": [1, 2} \"unterminated", InvalidUTF8(RejectInvalid))
+
+	if expected := []string{"echo", "b", "d", `{"a": [1, 2} "unterminated`}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+
+	if len(errs) != 5 {
+		test.Errorf("expected 5 errors, got %v", errs)
+	}
+
+	if _, errs := GetArgsLenient(`echo "unterminated`, Strict()); len(errs) != 1 {
+		test.Errorf("expected 1 error, got %v", errs)
+	}
+}
@@ -0,0 +1,75 @@
+package args
+
+import (
+	"sort"
+	"sync"
+)
+
+// SHELL_SYMBOLS are the shell metacharacters, returned as symbols by the posix and bash dialects
+const SHELL_SYMBOLS = "|&;<>()"
+
+var (
+	dialectsLock sync.RWMutex
+	dialects     = map[string][]GetArgsOption{
+		"default":    nil,
+		"posix":      {POSIXQuotes(), Concat(), NoBrackets(), SymbolChars(SHELL_SYMBOLS), Comments(WordStartComments)},
+		"bash":       {POSIXQuotes(), Concat(), NoBrackets(), SymbolChars(SHELL_SYMBOLS), Comments(WordStartComments), ShellOperators()},
+		"windows":    {DOSOptions(), NoBrackets(), NoSymbols(), QuoteChars(`"`)},
+		"powershell": {EscapeChar('`'), POSIXQuotes(), Comments(WordStartComments)},
+		"rc":         {NoEscape(), POSIXQuotes(), Concat(), NoBrackets(), Comments(WordStartComments)},
+		"systemd":    {SystemdExec()},
+		"make":       {MakeRecipe()},
+	}
+)
+
+// RegisterDialect registers (or replaces) a named set of scanner options, so that the parsing behavior
+// can be selected by name (i.e. from a configuration file). The predefined dialects are:
+//   - default: no options
+//   - posix: POSIX shell quoting (literal single quotes, concatenated segments, comments)
+//   - bash: posix, plus multi-character shell operators
+//   - windows: /flag options, only double quotes, no escapes, brackets or symbols
+//   - powershell: backtick escapes, literal single quotes, comments
+//   - rc: Plan 9 rc, with no escapes and literal single quotes (doubled quotes are not supported)
+//   - systemd: see SystemdExec
+//   - make: see MakeRecipe
+//
+// A dialect is a list of scanner options rather than a Spec, since in this package a Spec declares
+// the options of a command, not how its command line is split.
+func RegisterDialect(name string, options ...GetArgsOption) {
+	dialectsLock.Lock()
+	defer dialectsLock.Unlock()
+
+	dialects[name] = options
+}
+
+// LookupDialect returns the dialect registered with the given name, as a single option
+// (i.e. GetArgs(line, dialect)). It is not called Lookup, that is the lookup of an option in Args.
+func LookupDialect(name string) (GetArgsOption, bool) {
+	dialectsLock.RLock()
+	defer dialectsLock.RUnlock()
+
+	options, ok := dialects[name]
+	if !ok {
+		return nil, false
+	}
+
+	return func(s *Scanner) {
+		for _, option := range options {
+			option(s)
+		}
+	}, true
+}
+
+// Dialects returns the names of the registered dialects, sorted
+func Dialects() []string {
+	dialectsLock.RLock()
+	defer dialectsLock.RUnlock()
+
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
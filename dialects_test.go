@@ -0,0 +1,55 @@
+package args
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDialects(test *testing.T) {
+	tests := []struct {
+		dialect  string
+		line     string
+		expected []string
+	}{
+		{"default", `a "b c" {d e}`, []string{"a", "b c", "{d e}"}},
+		{"posix", `echo 'a\b' x"y z" {a b} # comment`, []string{"echo", `a\b`, "xy z", "{a", "b}"}},
+		{"posix", `ls |wc`, []string{"ls", "|", "wc"}},
+		{"bash", `a&&b 2>&1`, []string{"a&&b", "2>&1"}},
+		{"windows", `copy /y C:\dir\file "D:\my dir"`, []string{"copy", "/y", `C:\dir\file`, `D:\my dir`}},
+		{"windows", `echo 'a b'`, []string{"echo", "'a", "b'"}},
+		{"powershell", "Write-Host \"a`\"b\" 'c`d' # comment", []string{"Write-Host", `a"b`, "c`d"}},
+		{"rc", `echo 'a\b c' a\b`, []string{"echo", `a\b c`, `a\b`}},
+	}
+
+	for _, t := range tests {
+		dialect, ok := LookupDialect(t.dialect)
+		if !ok {
+			test.Errorf("dialect %v not found", t.dialect)
+			continue
+		}
+
+		if args := GetArgs(t.line, dialect); !reflect.DeepEqual(args, t.expected) {
+			test.Errorf("%v: expected %q got %q", t.dialect, t.expected, args)
+		}
+	}
+
+	if _, ok := LookupDialect("unknown"); ok {
+		test.Errorf("unexpected dialect")
+	}
+
+	RegisterDialect("csv", UserTokens(","), NoSymbols())
+	defer func() {
+		dialectsLock.Lock()
+		delete(dialects, "csv")
+		dialectsLock.Unlock()
+	}()
+
+	dialect, _ := LookupDialect("csv")
+	if args, expected := GetArgs("a,b", dialect), []string{"a", ",", "b"}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+
+	if names, expected := Dialects(), []string{"bash", "csv", "default", "make", "posix", "powershell", "rc", "systemd", "windows"}; !reflect.DeepEqual(names, expected) {
+		test.Errorf("expected %q got %q", expected, names)
+	}
+}
@@ -0,0 +1,221 @@
+package args
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind is the kind of a Change
+type ChangeKind int
+
+const (
+	Added    ChangeKind = iota // the option or argument is only in the new command line
+	Removed                    // the option or argument is only in the old command line
+	Modified                   // the option or argument has a different value
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Change is a difference between two command lines (see Diff)
+type Change struct {
+	Kind   ChangeKind
+	Option string // the option name (empty for arguments)
+	Index  int    // the argument index (-1 for options)
+	Old    string // the old value (empty if Added)
+	New    string // the new value (empty if Removed)
+}
+
+func (c Change) String() string {
+	name := "argument " + strconv.Itoa(c.Index)
+	if c.Index < 0 {
+		name = optionFlag(c.Option)
+	}
+
+	switch c.Kind {
+	case Added:
+		return "+" + name + " " + strconv.Quote(c.New)
+	case Removed:
+		return "-" + name + " " + strconv.Quote(c.Old)
+	}
+
+	return "~" + name + " " + strconv.Quote(c.Old) + " -> " + strconv.Quote(c.New)
+}
+
+// optionValues is a command line split in options (with the list of values of each option, in order)
+// and positional arguments
+type optionValues struct {
+	options   map[string][]string
+	arguments []string
+}
+
+// splitOptions separates the options (-name, --name, -name=value) from the positional arguments,
+// anywhere in the command line (up to "--"). Options in values take the value in the next argument.
+func splitOptions(args []string, values map[string]bool) optionValues {
+	parsed := optionValues{options: map[string][]string{}, arguments: []string{}}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			parsed.arguments = append(parsed.arguments, args[i+1:]...)
+			break
+		}
+
+		if len(arg) < 2 || arg[0] != '-' {
+			parsed.arguments = append(parsed.arguments, arg)
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)
+		if len(parts) == 1 && values[parts[0]] && i+1 < len(args) {
+			parts = append(parts, args[i+1])
+			i++
+		}
+
+		if len(parts) == 2 {
+			parsed.options[parts[0]] = append(parsed.options[parts[0]], parts[1])
+		} else {
+			parsed.options[parts[0]] = append(parsed.options[parts[0]], "")
+		}
+	}
+
+	return parsed
+}
+
+// Differ compares command lines (see Diff)
+type Differ struct {
+	ValueOptions []string        // options that take the value in the next argument (-k v is the same as -k=v)
+	Options      []GetArgsOption // options used to split the command lines
+}
+
+// Diff compares two command lines at the option and argument level, ignoring differences in quoting,
+// spacing and option order. Options (-name or --name, with an optional =value) are recognized anywhere
+// before "--". Options are returned first (sorted by name), then positional arguments.
+// Repeated options and positional arguments are compared as lists, in order (i.e. removing one of
+// several --exclude options is reported as a single change).
+func Diff(a, b string, options ...GetArgsOption) []Change {
+	d := Differ{Options: options}
+	return d.Diff(a, b)
+}
+
+// Diff compares two command lines (see Diff)
+func (d Differ) Diff(a, b string) []Change {
+	values := map[string]bool{}
+	for _, name := range d.ValueOptions {
+		values[strings.TrimLeft(name, "-")] = true
+	}
+
+	pa := splitOptions(GetArgs(a, d.Options...), values)
+	pb := splitOptions(GetArgs(b, d.Options...), values)
+
+	changes := []Change{}
+
+	names := []string{}
+	for name := range pa.options {
+		names = append(names, name)
+	}
+	for name := range pb.options {
+		if _, ok := pa.options[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		va, vb := pa.options[name], pb.options[name]
+
+		diffValues(va, vb, func(kind ChangeKind, i, j int) {
+			c := Change{Kind: kind, Option: name, Index: -1}
+			if i >= 0 {
+				c.Old = va[i]
+			}
+			if j >= 0 {
+				c.New = vb[j]
+			}
+			changes = append(changes, c)
+		})
+	}
+
+	diffValues(pa.arguments, pb.arguments, func(kind ChangeKind, i, j int) {
+		switch kind {
+		case Added:
+			changes = append(changes, Change{Kind: Added, Index: j, New: pb.arguments[j]})
+		case Removed:
+			changes = append(changes, Change{Kind: Removed, Index: i, Old: pa.arguments[i]})
+		default:
+			changes = append(changes, Change{Kind: Modified, Index: i, Old: pa.arguments[i], New: pb.arguments[j]})
+		}
+	})
+
+	return changes
+}
+
+// diffValues compares two lists, aligned on their longest common subsequence, and calls change
+// for each difference, with the index in a (or -1) and the index in b (or -1).
+// Removed values followed by added ones are reported as modified.
+func diffValues(a, b []string, change func(kind ChangeKind, i, j int)) {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	removed, added := []int{}, []int{}
+
+	flush := func() {
+		for k := 0; k < len(removed) || k < len(added); k++ {
+			switch {
+			case k >= len(removed):
+				change(Added, -1, added[k])
+			case k >= len(added):
+				change(Removed, removed[k], -1)
+			default:
+				change(Modified, removed[k], added[k])
+			}
+		}
+
+		removed, added = removed[:0], added[:0]
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			i++
+			j++
+		case j >= len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, i)
+			i++
+		default:
+			added = append(added, j)
+			j++
+		}
+	}
+
+	flush()
+}
@@ -0,0 +1,71 @@
+package args
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(test *testing.T) {
+	if changes := Diff(`--port=80  -v "my app"`, `-v --port="80" 'my app'`, Concat()); len(changes) != 0 {
+		test.Errorf("expected no changes, got %v", changes)
+	}
+
+	changes := Diff(`--port=80 -v --name=a serve x y`, `serve --port=8080 --debug --name=a z -- -v`)
+	expected := []Change{
+		{Kind: Added, Option: "debug", Index: -1},
+		{Kind: Modified, Option: "port", Index: -1, Old: "80", New: "8080"},
+		{Kind: Removed, Option: "v", Index: -1},
+		{Kind: Modified, Index: 1, Old: "x", New: "z"},
+		{Kind: Modified, Index: 2, Old: "y", New: "-v"},
+	}
+
+	if !reflect.DeepEqual(changes, expected) {
+		test.Errorf("expected %v got %v", expected, changes)
+	}
+}
+
+func TestDiffRepeatedOptions(test *testing.T) {
+	changes := Diff(`x --v=1 --v=2`, `x --v=2`)
+	expected := []Change{
+		{Kind: Removed, Option: "v", Index: -1, Old: "1"},
+	}
+
+	if !reflect.DeepEqual(changes, expected) {
+		test.Errorf("expected %v got %v", expected, changes)
+	}
+
+	changes = Diff(`x --v=1 --v=2`, `x --v=2 --v=1`)
+	if len(changes) == 0 {
+		test.Errorf("expected changes for reordered values")
+	}
+
+	changes = Diff(`rsync --exclude a --exclude b src`, `rsync --exclude b src`)
+	expected = []Change{
+		{Kind: Removed, Option: "exclude", Index: -1},
+		{Kind: Removed, Index: 1, Old: "a"},
+	}
+
+	if !reflect.DeepEqual(changes, expected) {
+		test.Errorf("expected %v got %v", expected, changes)
+	}
+
+	d := Differ{ValueOptions: []string{"--exclude"}}
+	changes = d.Diff(`rsync --exclude a --exclude b src`, `rsync --exclude b src`)
+	expected = []Change{
+		{Kind: Removed, Option: "exclude", Index: -1, Old: "a"},
+	}
+
+	if !reflect.DeepEqual(changes, expected) {
+		test.Errorf("expected %v got %v", expected, changes)
+	}
+}
+
+func ExampleDiff() {
+	for _, c := range Diff(`deploy --replicas=2 web`, `deploy --replicas=3 --canary web`) {
+		fmt.Println(c)
+	}
+	// Output:
+	// +--canary ""
+	// ~--replicas "2" -> "3"
+}
@@ -0,0 +1,92 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// ShellFormToExecForm converts a Dockerfile shell-form command (RUN/CMD/ENTRYPOINT command)
+// into the equivalent exec-form JSON array.
+// Commands that need a shell (operators, redirections, variables, globs) are wrapped in ["/bin/sh", "-c", ...]
+func ShellFormToExecForm(cmd string) (string, error) {
+	argv, err := shellFormArgs(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(argv); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+func shellFormArgs(cmd string) ([]string, error) {
+	cmd = strings.TrimSpace(cmd)
+
+	scanner := getScanner(cmd, Concat(), POSIXQuotes(), ShellOperators(), TrackSegments(),
+		NoBrackets(), Comments(WordStartComments), Strict())
+
+	argv := []string{}
+	shell := false
+
+	for {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if tok.Type != WordToken {
+			shell = true
+		}
+
+		for _, seg := range tok.Segments {
+			switch seg.Quote {
+			case NO_QUOTE:
+				if strings.ContainsAny(cmd[seg.Start:seg.End], "$`;&*?~") {
+					shell = true
+				}
+
+			case '"':
+				if strings.ContainsAny(seg.Text, "$`") {
+					shell = true
+				}
+			}
+		}
+
+		argv = append(argv, tok.Value)
+	}
+
+	if shell {
+		return []string{"/bin/sh", "-c", cmd}, nil
+	}
+
+	return argv, nil
+}
+
+// ExecFormToShellForm converts a Dockerfile exec-form JSON array into the equivalent shell-form command,
+// quoting the arguments as needed. The "/bin/sh -c" wrapper, if present, is removed.
+func ExecFormToShellForm(form string) (string, error) {
+	var argv []string
+
+	if err := json.Unmarshal([]byte(form), &argv); err != nil {
+		return "", err
+	}
+
+	if len(argv) == 3 && (argv[0] == "/bin/sh" || argv[0] == "sh") && argv[1] == "-c" {
+		return argv[2], nil
+	}
+
+	return Join(argv), nil
+}
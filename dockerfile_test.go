@@ -0,0 +1,40 @@
+//go:build !tinygo
+
+package args
+
+import "testing"
+
+func TestShellFormToExecForm(test *testing.T) {
+	for cmd, expected := range map[string]string{
+		`apt-get install -y "my package" 'x'`: `["apt-get","install","-y","my package","x"]`,
+		`make && make install`:                `["/bin/sh","-c","make && make install"]`,
+		`echo "$HOME"`:                        `["/bin/sh","-c","echo \"$HOME\""]`,
+		`echo '$HOME' # comment`:              `["echo","$HOME"]`,
+		`rm *.o`:                              `["/bin/sh","-c","rm *.o"]`,
+	} {
+		form, err := ShellFormToExecForm(cmd)
+		if err != nil {
+			test.Error(err)
+		}
+
+		if form != expected {
+			test.Errorf("%v: expected %v got %v", cmd, expected, form)
+		}
+	}
+}
+
+func TestExecFormToShellForm(test *testing.T) {
+	for form, expected := range map[string]string{
+		`["apt-get", "install", "-y", "my package"]`: `apt-get install -y "my package"`,
+		`["/bin/sh", "-c", "make && make install"]`:  `make && make install`,
+	} {
+		cmd, err := ExecFormToShellForm(form)
+		if err != nil {
+			test.Error(err)
+		}
+
+		if cmd != expected {
+			test.Errorf("%v: expected %v got %v", form, expected, cmd)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package args
+
+import (
+	"io"
+	"strings"
+)
+
+// ParseEnvFile parses a dotenv-style file (KEY=value lines) using the scanner quoting rules:
+//
+//   - values can be double quoted (with C-style escapes, i.e. \n, and spanning multiple lines) or single quoted (literal)
+//   - unquoted values extend to the end of the line, and a backslash-newline continues the value on the next line
+//   - comments start with # at the beginning of a word, and the "export" prefix is ignored
+func ParseEnvFile(r io.Reader) (map[string]string, error) {
+	scanner := NewScanner(r)
+	scanner.Concat = true
+	scanner.POSIXQuotes = true
+	scanner.QuotedCEscapes = true
+	scanner.NoSymbols = true
+	scanner.NoBrackets = true
+	scanner.ASCIISpaces = true
+	scanner.Comments = WordStartComments
+	scanner.TrackSegments = true
+	scanner.Strict = true
+
+	env := map[string]string{}
+	key := ""   // current key
+	line := 0   // line of the current key
+	export := 0 // line of the last "export" prefix
+
+	for {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			return env, nil
+		}
+		if err != nil {
+			return env, err
+		}
+
+		if tok.Line == line && key != "" {
+			// unquoted value with spaces
+			env[key] += " " + envValue(tok)
+			continue
+		}
+
+		if tok.Value == "export" && !tok.Quoted && export != tok.Line {
+			export = tok.Line
+			continue
+		}
+
+		parts := strings.SplitN(envValue(tok), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return env, &ParseError{Offset: tok.Segments[0].Start, Err: ErrInvalidEnv, Detail: tok.Value}
+		}
+
+		key, line = parts[0], tok.Line
+		env[key] = parts[1]
+	}
+}
+
+// envValue returns the token value, without the escaped newlines outside quotes (line continuations)
+func envValue(tok Token) string {
+	if !strings.Contains(tok.Value, "\n") {
+		return tok.Value
+	}
+
+	var b strings.Builder
+
+	for _, seg := range tok.Segments {
+		if seg.Quote == NO_QUOTE {
+			b.WriteString(strings.ReplaceAll(seg.Text, "\n", ""))
+		} else {
+			b.WriteString(seg.Text)
+		}
+	}
+
+	return b.String()
+}
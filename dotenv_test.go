@@ -0,0 +1,56 @@
+package args
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEnvFile(test *testing.T) {
+	env, err := ParseEnvFile(strings.NewReader(`
+# database settings
+DB_HOST=localhost
+DB_NAME="my db" # inline comment
+export DB_PASSWORD='p@ss\word#1'
+GREETING=hello world
+MULTI="line one
+line two\ttabbed"
+ESCAPED="one\ntwo"
+CONTINUED=first\
+second
+B=abc\
+def" x\ny"
+WINDOWS=C:\temp\new
+EMPTY=
+`))
+
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"DB_HOST":     "localhost",
+		"DB_NAME":     "my db",
+		"DB_PASSWORD": `p@ss\word#1`,
+		"GREETING":    "hello world",
+		"MULTI":       "line one\nline two\ttabbed",
+		"ESCAPED":     "one\ntwo",
+		"CONTINUED":   "firstsecond",
+		"B":           "abcdef x\ny",
+		"WINDOWS":     "C:tempnew",
+		"EMPTY":       "",
+	}
+
+	if !reflect.DeepEqual(env, expected) {
+		test.Errorf("expected %q got %q", expected, env)
+	}
+
+	if _, err := ParseEnvFile(strings.NewReader("KEY=1\nnot a key\n")); !errors.Is(err, ErrInvalidEnv) {
+		test.Errorf("expected ErrInvalidEnv, got %v", err)
+	}
+
+	if _, err := ParseEnvFile(strings.NewReader(`KEY="unterminated`)); !errors.Is(err, ErrUnterminatedQuote) {
+		test.Errorf("expected ErrUnterminatedQuote, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package args
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// SetOption sets the value of an option (added after the existing options, if new)
+func (a *Args) SetOption(name, value string) {
+	if a.Options == nil {
+		a.Options = map[string]string{}
+	}
+	if a.HasValue == nil {
+		a.HasValue = map[string]bool{}
+	}
+
+	a.Options[name] = value
+	a.HasValue[name] = true
+}
+
+// DeleteOption removes an option
+func (a *Args) DeleteOption(name string) {
+	delete(a.Options, name)
+	delete(a.HasValue, name)
+	delete(a.Positions, name)
+}
+
+// AppendArgument adds an argument at the end of the arguments
+func (a *Args) AppendArgument(arg string) {
+	a.Arguments = append(a.Arguments, arg)
+}
+
+// InsertArgument inserts an argument at position i (0 inserts it as the first argument).
+// If i is out of range, the argument is appended.
+func (a *Args) InsertArgument(i int, arg string) {
+	if i < 0 || i >= len(a.Arguments) {
+		a.AppendArgument(arg)
+		return
+	}
+
+	a.Arguments = append(a.Arguments[:i], append([]string{arg}, a.Arguments[i:]...)...)
+}
+
+// String returns the command line for the parsed arguments: environment assignments, options
+// (in their original order, followed by new options sorted by name) and arguments, quoted as needed (see Join).
+// A "--" is added before the arguments if the first argument looks like an option.
+func (a Args) String() string {
+	names := make([]string, 0, len(a.Options))
+	for name := range a.Options {
+		names = append(names, name)
+	}
+
+	index := func(name string) int {
+		if p, ok := a.Positions[name]; ok {
+			return p.Index
+		}
+
+		return math.MaxInt32
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if ii, ij := index(names[i]), index(names[j]); ii != ij {
+			return ii < ij
+		}
+
+		return names[i] < names[j]
+	})
+
+	words := []string{}
+
+	for _, env := range a.Env {
+		// the name must not be quoted, or it's not an assignment
+		if n := strings.IndexByte(env, '='); n > 0 {
+			words = append(words, env[:n+1]+Quote(env[n+1:]))
+		} else {
+			words = append(words, Quote(env))
+		}
+	}
+
+	args := []string{}
+
+	for _, name := range names {
+		opt := optionFlag(name)
+		if value := a.Options[name]; value != "" || a.HasValue[name] {
+			opt += "=" + value
+		}
+
+		args = append(args, opt)
+	}
+
+	if len(a.Arguments) > 0 && strings.HasPrefix(a.Arguments[0], "-") {
+		args = append(args, "--")
+	}
+
+	for _, arg := range args {
+		words = append(words, Quote(arg))
+	}
+
+	for i, arg := range a.Arguments {
+		if q := Quote(arg); i == 0 && len(args) == 0 && q == arg && isAssignment(arg) {
+			// a leading NAME=value argument would be read as an assignment
+			words = append(words, `"`+arg+`"`)
+		} else {
+			words = append(words, q)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the command line (see String)
+func (a Args) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing the command line
+// (as ParseArgs, with EnvAssignments)
+func (a *Args) UnmarshalText(text []byte) error {
+	*a = ParseArgs(string(text), EnvAssignments())
+	return nil
+}
@@ -0,0 +1,108 @@
+package args
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestArgsEdit(test *testing.T) {
+	parsed := ParseArgs(`--zone=eu -v --label= deploy "my app"`)
+
+	parsed.SetOption("replicas", "3")
+	parsed.SetOption("zone", "us")
+	parsed.DeleteOption("v")
+	parsed.InsertArgument(1, "--force")
+	parsed.AppendArgument("now")
+
+	if s, expected := parsed.String(), `--zone=us --label= --replicas=3 deploy --force "my app" now`; s != expected {
+		test.Errorf("expected %v got %v", expected, s)
+	}
+
+	var empty Args
+	empty.SetOption("n", "1")
+	empty.InsertArgument(5, "-x")
+
+	if s, expected := empty.String(), `-n=1 -- -x`; s != expected {
+		test.Errorf("expected %v got %v", expected, s)
+	}
+
+	parsed = ParseArgs(`A=1 -x y`, EnvAssignments())
+	if s, expected := parsed.String(), `A=1 -x y`; s != expected {
+		test.Errorf("expected %v got %v", expected, s)
+	}
+
+	parsed = ParseArgs(`A="1 2" B= x`, EnvAssignments())
+	if s, expected := parsed.String(), `A="1 2" B="" x`; s != expected {
+		test.Errorf("expected %v got %v", expected, s)
+	}
+}
+
+func TestArgsAssignmentRoundTrip(test *testing.T) {
+	for _, a := range []Args{
+		{Arguments: []string{"FOO=bar", "x"}},
+		{Env: []string{"A=1"}, Arguments: []string{"FOO=bar", "x"}},
+		{Env: []string{"A=x y"}, Arguments: []string{"FOO=a b"}},
+	} {
+		text, _ := a.MarshalText()
+
+		var b Args
+		if err := b.UnmarshalText(text); err != nil {
+			test.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(b.Env, append([]string{}, a.Env...)) || !reflect.DeepEqual(b.Arguments, a.Arguments) {
+			test.Errorf("%s: expected env %q arguments %q got %q %q", text, a.Env, a.Arguments, b.Env, b.Arguments)
+		}
+	}
+}
+
+func TestArgsText(test *testing.T) {
+	var config struct {
+		Name    string
+		Command Args
+	}
+
+	input := `{"Name":"build","Command":"CGO_ENABLED=0 \"--out=my bin\" -v go build ./..."}`
+	if err := json.Unmarshal([]byte(input), &config); err != nil {
+		test.Fatal(err)
+	}
+
+	if expected := []string{"CGO_ENABLED=0"}; !reflect.DeepEqual(config.Command.Env, expected) {
+		test.Errorf("expected env %q got %q", expected, config.Command.Env)
+	}
+	if out := config.Command.GetOption("out", ""); out != "my bin" {
+		test.Errorf("expected %q got %q", "my bin", out)
+	}
+	if expected := []string{"go", "build", "./..."}; !reflect.DeepEqual(config.Command.Arguments, expected) {
+		test.Errorf("expected arguments %q got %q", expected, config.Command.Arguments)
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	var decoded struct {
+		Name    string
+		Command Args
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		test.Fatal(err)
+	}
+
+	if s, expected := decoded.Command.String(), config.Command.String(); s != expected {
+		test.Errorf("expected %v got %v", expected, s)
+	}
+}
+
+func ExampleArgs_String() {
+	parsed := ParseArgs(`--verbose --out=a.txt convert "my file.png"`)
+	parsed.SetOption("out", "b.txt")
+	parsed.DeleteOption("verbose")
+
+	fmt.Println(parsed)
+	// Output:
+	// --out=b.txt convert "my file.png"
+}
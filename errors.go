@@ -0,0 +1,105 @@
+package args
+
+import (
+	"errors"
+	"strconv"
+)
+
+var (
+	ErrUnterminatedQuote  = errors.New("unterminated quote")
+	ErrUnbalancedBracket  = errors.New("unbalanced bracket")
+	ErrMismatchedBracket  = errors.New("mismatched bracket")
+	ErrTrailingEscape     = errors.New("trailing escape character")
+	ErrTooManyTokens      = errors.New("too many tokens")
+	ErrTokenTooLarge      = errors.New("token too large")
+	ErrInvalidUTF8        = errors.New("invalid UTF-8")
+	ErrMissingOption      = errors.New("missing required option")
+	ErrMissingArgument    = errors.New("missing required argument")
+	ErrConflictingOptions = errors.New("mutually exclusive options")
+	ErrAmbiguousOption    = errors.New("ambiguous option")
+	ErrInvalidValue       = errors.New("invalid value")
+	ErrInvalidCrontab     = errors.New("invalid crontab line")
+	ErrInvalidEnv         = errors.New("invalid environment line")
+	ErrInvalidINI         = errors.New("invalid INI line")
+	ErrInvalidJSON        = errors.New("invalid JSON")
+	ErrInvalidLiteral     = errors.New("invalid literal")
+	ErrInvalidMacro       = errors.New("invalid macro definition")
+	ErrMacroRecursion     = errors.New("macro recursion too deep")
+	ErrArgTooLong         = errors.New("argument too long")
+	ErrNoSuchToken        = errors.New("no such token")
+	ErrInvalidCache       = errors.New("invalid token cache")
+	ErrUnsafeInput        = errors.New("unsafe input")
+	ErrInvalidShebang     = errors.New("invalid shebang line")
+)
+
+// ParseError describes a problem found in the input, at the specified offset.
+// Use errors.Is to check the kind of error (i.e. errors.Is(err, ErrUnterminatedQuote))
+type ParseError struct {
+	Offset int    // byte offset of the problem in the input
+	Err    error  // the kind of error (one of the Err* variables)
+	Detail string // optional details
+}
+
+func (e *ParseError) Error() string {
+	if e.Detail != "" {
+		return e.Err.Error() + " at offset " + strconv.Itoa(e.Offset) + ": " + e.Detail
+	}
+
+	return e.Err.Error() + " at offset " + strconv.Itoa(e.Offset)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidUTF8Error is returned when the Scanner finds an invalid UTF-8 sequence and the policy is RejectInvalid
+type InvalidUTF8Error struct {
+	Offset int // byte offset of the invalid sequence in the input
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return "invalid UTF-8 at offset " + strconv.Itoa(e.Offset)
+}
+
+func (e *InvalidUTF8Error) Unwrap() error {
+	return ErrInvalidUTF8
+}
+
+// wrapError is an error with its own message, wrapping another error (as fmt.Errorf with %w)
+type wrapError struct {
+	msg string
+	err error
+}
+
+func (e *wrapError) Error() string {
+	return e.msg
+}
+
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
+
+// wrap returns an error with the message msg, wrapping err
+func wrap(err error, msg string) error {
+	return &wrapError{msg: msg, err: err}
+}
+
+// wrapDetail returns an error with the message "err: detail", wrapping err
+func wrapDetail(err error, detail string) error {
+	return wrap(err, err.Error()+": "+detail)
+}
+
+// errorOffset returns the offset of a ParseError or InvalidUTF8Error, or def for other errors
+func errorOffset(err error, def int) int {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		return pe.Offset
+	}
+
+	var ue *InvalidUTF8Error
+	if errors.As(err, &ue) {
+		return ue.Offset
+	}
+
+	return def
+}
@@ -0,0 +1,49 @@
+package args
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrors(test *testing.T) {
+	scanner := NewScannerString("one two three four")
+	scanner.MaxTokens = 2
+
+	tokens, err := scanner.GetTokens()
+	if len(tokens) != 2 || !errors.Is(err, ErrTooManyTokens) {
+		test.Errorf("expected 2 tokens and ErrTooManyTokens, got %q %v", tokens, err)
+	}
+
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Offset != 8 {
+		test.Errorf("expected ParseError at offset 8, got %#v", err)
+	}
+
+	scanner = NewScannerString("a\xffb")
+	scanner.InvalidUTF8 = RejectInvalid
+
+	_, err = scanner.GetTokens()
+	if !errors.Is(err, ErrInvalidUTF8) {
+		test.Errorf("expected ErrInvalidUTF8, got %v", err)
+	}
+}
+
+func TestTokenTooLarge(test *testing.T) {
+	huge := "ok |" + strings.Repeat("x", 1000)
+
+	tokens, err := GetArgsStrict(huge, MaxTokenSize(16))
+	if !errors.Is(err, ErrTokenTooLarge) {
+		test.Errorf("expected ErrTokenTooLarge, got %q %v", tokens, err)
+	}
+
+	scanner := NewScannerString("-v " + strings.Repeat("y ", 100))
+	scanner.MaxTokenSize = 16
+	if _, _, err := scanner.GetOptionTokens(); !errors.Is(err, ErrTokenTooLarge) {
+		test.Errorf("expected ErrTokenTooLarge for the remainder, got %v", err)
+	}
+
+	if args := GetArgs("one two three", MaxTokenSize(5)); len(args) != 3 {
+		test.Errorf("expected 3 tokens, got %q", args)
+	}
+}
@@ -0,0 +1,110 @@
+package args
+
+import (
+	"bytes"
+	"io"
+)
+
+// Feeder is a push-based tokenizer: the input is provided in chunks (see Feed), as it becomes available,
+// and the tokens are returned as soon as they are complete. Offsets and lines in the returned tokens
+// are relative to the whole input.
+type Feeder struct {
+	options []GetArgsOption
+	buf     []byte // input not consumed yet
+	offset  int    // offset of buf in the input
+	line    int    // number of newlines before buf
+	argPos  bool   // see Scanner.argPos
+}
+
+// NewFeeder returns a Feeder that splits the input according to the options (see GetArgs)
+func NewFeeder(options ...GetArgsOption) *Feeder {
+	return &Feeder{options: options}
+}
+
+// Feed adds a chunk of input and returns the tokens that are complete.
+// A token at the end of the chunk is kept until more input (or Flush) shows where it ends.
+func (f *Feeder) Feed(chunk []byte) ([]Token, error) {
+	f.buf = append(f.buf, chunk...)
+	return f.scan(false)
+}
+
+// Flush processes the rest of the input, as if the end of the input was reached, and returns the last tokens.
+// The Feeder can then be reused for a new input.
+func (f *Feeder) Flush() ([]Token, error) {
+	tokens, err := f.scan(true)
+
+	f.buf = nil
+	f.offset = 0
+	f.line = 0
+	f.argPos = false
+	return tokens, err
+}
+
+// scan returns the complete tokens in buf, and removes the corresponding input
+func (f *Feeder) scan(eof bool) ([]Token, error) {
+	scanner := getScanner(string(f.buf), f.options...)
+	scanner.argPos = f.argPos
+
+	if !eof {
+		// errors at the end of the input (i.e. unterminated quotes) may be fixed by the next chunk
+		scanner.Strict = false
+	}
+
+	tokens := []Token{}
+	consumed := 0 // input consumed by the complete tokens
+	complete := 0 // number of complete tokens (a token split by Classify is complete when all parts are)
+
+	for {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if !eof && scanner.offset >= len(f.buf) {
+				break
+			}
+
+			f.consume(consumed)
+			return tokens[:complete], err
+		}
+
+		if !eof {
+			if tok.End == EndEOF {
+				break
+			}
+
+			if scanner.offset >= len(f.buf) && tok.End != EndSpace && tok.End != EndUserToken {
+				// the token (i.e. an operator or a quoted segment) may continue in the next chunk
+				break
+			}
+		}
+
+		tok.Line += f.line
+		for i := range tok.Segments {
+			tok.Segments[i].Start += f.offset
+			tok.Segments[i].End += f.offset
+		}
+
+		tokens = append(tokens, tok)
+
+		if len(scanner.pending) == 0 {
+			consumed = scanner.offset
+			complete = len(tokens)
+			f.argPos = scanner.argPos
+		}
+	}
+
+	if eof {
+		consumed, complete = len(f.buf), len(tokens)
+	}
+
+	f.consume(consumed)
+	return tokens[:complete], nil
+}
+
+// consume removes the first n bytes from buf
+func (f *Feeder) consume(n int) {
+	f.line += bytes.Count(f.buf[:n], []byte{'\n'})
+	f.offset += n
+	f.buf = append(f.buf[:0], f.buf[n:]...)
+}
@@ -0,0 +1,87 @@
+package args
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func feedValues(tokens []Token) []string {
+	values := []string{}
+	for _, tok := range tokens {
+		values = append(values, tok.Value)
+	}
+	return values
+}
+
+func TestFeeder(test *testing.T) {
+	input := "echo \"hello world\" a&&b\n{\"x\": [1, 2]} last"
+
+	expected := GetArgs(input, ShellOperators())
+
+	// feed the input in chunks of every size
+	for size := 1; size <= len(input); size++ {
+		feeder := NewFeeder(ShellOperators())
+		values := []string{}
+
+		for i := 0; i < len(input); i += size {
+			end := i + size
+			if end > len(input) {
+				end = len(input)
+			}
+
+			tokens, err := feeder.Feed([]byte(input[i:end]))
+			if err != nil {
+				test.Fatalf("size %v: %v", size, err)
+			}
+
+			values = append(values, feedValues(tokens)...)
+		}
+
+		tokens, err := feeder.Flush()
+		if err != nil {
+			test.Fatalf("size %v: %v", size, err)
+		}
+
+		if values = append(values, feedValues(tokens)...); !reflect.DeepEqual(values, expected) {
+			test.Errorf("size %v: expected %q got %q", size, expected, values)
+		}
+	}
+}
+
+func TestFeederPositions(test *testing.T) {
+	feeder := NewFeeder(TrackSegments())
+
+	tokens, _ := feeder.Feed([]byte("one tw"))
+	if values := feedValues(tokens); !reflect.DeepEqual(values, []string{"one"}) {
+		test.Errorf("expected [one] got %q", values)
+	}
+
+	tokens, _ = feeder.Feed([]byte("o\nthree 'four"))
+	if values := feedValues(tokens); !reflect.DeepEqual(values, []string{"two", "three"}) {
+		test.Errorf("expected [two three] got %q", values)
+	}
+
+	if seg := tokens[1].Segments[0]; tokens[1].Line != 2 || seg.Start != 8 || seg.End != 13 {
+		test.Errorf("unexpected position for three: line %v %+v", tokens[1].Line, seg)
+	}
+
+	tokens, _ = feeder.Feed([]byte(" five'"))
+	if len(tokens) != 0 {
+		test.Errorf("expected no tokens got %q", feedValues(tokens))
+	}
+
+	tokens, err := feeder.Flush()
+	if values := feedValues(tokens); err != nil || !reflect.DeepEqual(values, []string{"four five"}) {
+		test.Errorf("expected [four five] got %q %v", values, err)
+	}
+
+	// strict errors are only reported at the end of the input
+	feeder = NewFeeder(Strict())
+	if _, err := feeder.Feed([]byte(`a "b`)); err != nil {
+		test.Errorf("unexpected error %v", err)
+	}
+	if _, err := feeder.Flush(); !errors.Is(err, ErrUnterminatedQuote) {
+		test.Errorf("expected unterminated quote error, got %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"flag"
+	"fmt"
+)
+
+// Create a new FlagSet to be used with ParseFlags
+func NewFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	flags.Usage = func() {
+		fmt.Printf("Usage of %s:\n", name)
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+// Parse the input line through the (initialized) FlagSet
+func ParseFlags(flags *flag.FlagSet, line string) error {
+	return flags.Parse(GetArgs(line))
+}
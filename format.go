@@ -0,0 +1,113 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format formats a command line like fmt.Sprintf, but the %q verb quotes the value with this package's rules
+// (see Quote) so that it's parsed back as a single argument (a []string is formatted as multiple arguments, see Join).
+// Other verbs (and width or precision operands) are formatted as in fmt: use %q for any value that comes from user input.
+func Format(format string, a ...interface{}) string {
+	values := append([]interface{}{}, a...)
+	for i := range quotedOperands(format) {
+		if i < len(values) {
+			values[i] = quoted{values[i]}
+		}
+	}
+
+	return fmt.Sprintf(format, values...)
+}
+
+// quoted formats a value for the %q verb of Format
+type quoted struct {
+	v interface{}
+}
+
+func (q quoted) Format(f fmt.State, verb rune) {
+	if verb != 'q' {
+		fmt.Fprintf(f, fmt.FormatString(f, verb), q.v)
+		return
+	}
+
+	if list, ok := q.v.([]string); ok {
+		io.WriteString(f, Join(list))
+	} else {
+		io.WriteString(f, Quote(fmt.Sprint(q.v)))
+	}
+}
+
+// quotedOperands returns the indexes of the operands formatted with %q, following the fmt rules
+// for operands consumed by * (width and precision) and explicit argument indexes ([n])
+func quotedOperands(format string) map[int]bool {
+	operands := map[int]bool{}
+	arg := 0
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+
+		i++
+
+		// explicit argument index, i.e. %[2]d
+		argIndex := func() {
+			if i < len(format) && format[i] == '[' {
+				if end := strings.IndexByte(format[i:], ']'); end > 0 {
+					if n, err := strconv.Atoi(format[i+1 : i+end]); err == nil && n > 0 {
+						arg = n - 1
+					}
+					i += end + 1
+				}
+			}
+		}
+
+		// width or precision: * consumes an operand
+		number := func() {
+			argIndex()
+			if i < len(format) && format[i] == '*' {
+				arg++
+				i++
+				return
+			}
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+		}
+
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+
+		number()
+		if i < len(format) && format[i] == '.' {
+			i++
+			number()
+		}
+
+		argIndex()
+		if i >= len(format) {
+			break
+		}
+
+		switch format[i] {
+		case '%':
+			continue
+		case 'q':
+			operands[arg] = true
+		}
+
+		arg++
+	}
+
+	return operands
+}
+
+// sprint formats a value of any type (see MapToArgs)
+func sprint(value interface{}) string {
+	return fmt.Sprint(value)
+}
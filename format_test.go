@@ -0,0 +1,47 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFormat(test *testing.T) {
+	pattern, file := `it's "x"`, "my file.txt"
+
+	line := Format("grep -n %q %q | head -%d", pattern, file, 5)
+	if expected := `grep -n "it's \"x\"" "my file.txt" | head -5`; line != expected {
+		test.Errorf("expected %v got %v", expected, line)
+	}
+
+	if args := GetArgs(line); !reflect.DeepEqual(args[:4], []string{"grep", "-n", pattern, file}) {
+		test.Errorf("unexpected parsing %q", args)
+	}
+
+	if line, expected := Format("rm %q %05.1f %v", []string{"a b", "c"}, 3.14159, "x"), `rm "a b" c 003.1 x`; line != expected {
+		test.Errorf("expected %v got %v", expected, line)
+	}
+
+	for _, c := range []struct {
+		format   string
+		args     []interface{}
+		expected string
+	}{
+		{"%*d|%q", []interface{}{5, 42, "a b"}, `   42|"a b"`},
+		{"%.*s %q", []interface{}{2, "abc", "x y"}, `ab "x y"`},
+		{"%[2]q %[1]d %%q", []interface{}{1, "a b"}, `"a b" 1 %q`},
+		{"%-*.*f|%q", []interface{}{6, 1, 2.25, 7}, `2.2   |7`},
+	} {
+		if line := Format(c.format, c.args...); line != c.expected {
+			test.Errorf("%v: expected %q got %q", c.format, c.expected, line)
+		}
+	}
+}
+
+func ExampleFormat() {
+	fmt.Println(Format("cp %q %q", "my file.txt", "backup/"))
+	// Output:
+	// cp "my file.txt" backup/
+}
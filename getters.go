@@ -0,0 +1,25 @@
+package args
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GetEnumOption returns the value of an option that must be one of the allowed values (compared ignoring case).
+// The value is returned as listed in allowed. It returns an ErrInvalidValue error listing the allowed values
+// if the value is not one of them.
+func (a Args) GetEnumOption(name string, allowed []string, def string) (string, error) {
+	val, ok := a.Options[name]
+	if !ok {
+		return def, nil
+	}
+
+	for _, v := range allowed {
+		if strings.EqualFold(v, val) {
+			return v, nil
+		}
+	}
+
+	return def, wrap(ErrInvalidValue, ErrInvalidValue.Error()+" for "+optionFlag(name)+": "+strconv.Quote(val)+
+		" (expected one of "+strings.Join(allowed, ", ")+")")
+}
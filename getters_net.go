@@ -0,0 +1,73 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// GetIPOption returns the value of an IP address option (IPv4 or IPv6).
+// It returns an ErrInvalidValue error if the value is not a valid address.
+func (a Args) GetIPOption(name string, def netip.Addr) (netip.Addr, error) {
+	val, ok := a.Options[name]
+	if !ok {
+		return def, nil
+	}
+
+	addr, err := netip.ParseAddr(val)
+	if err != nil {
+		return def, fmt.Errorf("%w for %s: %v", ErrInvalidValue, optionFlag(name), err)
+	}
+
+	return addr, nil
+}
+
+// GetCIDROption returns the value of a CIDR option (i.e. 10.0.0.0/8 or fd00::/8).
+// It returns an ErrInvalidValue error if the value is not a valid prefix.
+func (a Args) GetCIDROption(name string, def netip.Prefix) (netip.Prefix, error) {
+	val, ok := a.Options[name]
+	if !ok {
+		return def, nil
+	}
+
+	prefix, err := netip.ParsePrefix(val)
+	if err != nil {
+		return def, fmt.Errorf("%w for %s: %v", ErrInvalidValue, optionFlag(name), err)
+	}
+
+	return prefix, nil
+}
+
+// GetURLOption returns the value of an absolute URL option. If schemes are specified, the URL scheme must be one of them.
+// It returns an ErrInvalidValue error if the value is not a valid URL or the scheme is not allowed.
+func (a Args) GetURLOption(name string, def *url.URL, schemes ...string) (*url.URL, error) {
+	val, ok := a.Options[name]
+	if !ok {
+		return def, nil
+	}
+
+	u, err := url.Parse(val)
+	if err != nil {
+		return def, fmt.Errorf("%w for %s: %v", ErrInvalidValue, optionFlag(name), err)
+	}
+
+	if !u.IsAbs() {
+		return def, fmt.Errorf("%w for %s: %q is not an absolute URL", ErrInvalidValue, optionFlag(name), val)
+	}
+
+	if len(schemes) == 0 {
+		return u, nil
+	}
+
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return u, nil
+		}
+	}
+
+	return def, fmt.Errorf("%w for %s: scheme %q not allowed (expected %s)",
+		ErrInvalidValue, optionFlag(name), u.Scheme, strings.Join(schemes, ", "))
+}
@@ -0,0 +1,69 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+func TestGetIPOption(test *testing.T) {
+	parsed := ParseArgs(`--listen=127.0.0.1 --peer=::1 --bad=300.1.1.1 --net=10.0.0.0/8 --net6=fd00::/8 --badnet=10.0.0.0/33`)
+	def := netip.MustParseAddr("0.0.0.0")
+
+	for name, expected := range map[string]string{
+		"listen":  "127.0.0.1",
+		"peer":    "::1",
+		"missing": "0.0.0.0",
+	} {
+		if addr, err := parsed.GetIPOption(name, def); err != nil || addr.String() != expected {
+			test.Errorf("%v: expected %v got %v %v", name, expected, addr, err)
+		}
+	}
+
+	if _, err := parsed.GetIPOption("bad", def); !errors.Is(err, ErrInvalidValue) {
+		test.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+
+	defnet := netip.MustParsePrefix("0.0.0.0/0")
+
+	for name, expected := range map[string]string{
+		"net":     "10.0.0.0/8",
+		"net6":    "fd00::/8",
+		"missing": "0.0.0.0/0",
+	} {
+		if prefix, err := parsed.GetCIDROption(name, defnet); err != nil || prefix.String() != expected {
+			test.Errorf("%v: expected %v got %v %v", name, expected, prefix, err)
+		}
+	}
+
+	if _, err := parsed.GetCIDROption("badnet", defnet); !errors.Is(err, ErrInvalidValue) {
+		test.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+}
+
+func TestGetURLOption(test *testing.T) {
+	parsed := ParseArgs(`--endpoint=https://example.com/api?x=1 --ftp=ftp://example.com --rel=/api --bad=http://[::1`)
+
+	u, err := parsed.GetURLOption("endpoint", nil, "http", "https")
+	if err != nil || u.Host != "example.com" || u.Path != "/api" {
+		test.Errorf("unexpected url %v %v", u, err)
+	}
+
+	def, _ := url.Parse("http://localhost")
+	if u, err := parsed.GetURLOption("missing", def); err != nil || u != def {
+		test.Errorf("expected default, got %v %v", u, err)
+	}
+
+	if u, err := parsed.GetURLOption("ftp", def); err != nil || u.Scheme != "ftp" {
+		test.Errorf("unexpected url %v %v", u, err)
+	}
+
+	for _, name := range []string{"ftp", "rel", "bad"} {
+		if _, err := parsed.GetURLOption(name, def, "http", "https"); !errors.Is(err, ErrInvalidValue) {
+			test.Errorf("%v: expected ErrInvalidValue, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package args
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetEnumOption(test *testing.T) {
+	parsed := ParseArgs(`--format=JSON --level=trace`)
+	formats := []string{"json", "yaml", "text"}
+
+	if v, err := parsed.GetEnumOption("format", formats, "text"); err != nil || v != "json" {
+		test.Errorf("expected json got %q %v", v, err)
+	}
+
+	if v, err := parsed.GetEnumOption("missing", formats, "text"); err != nil || v != "text" {
+		test.Errorf("expected text got %q %v", v, err)
+	}
+
+	_, err := parsed.GetEnumOption("level", []string{"debug", "info"}, "info")
+	if !errors.Is(err, ErrInvalidValue) || err.Error() != `invalid value for --level: "trace" (expected one of debug, info)` {
+		test.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"fmt"
+	"time"
+)
+
+// GetTimeOption returns the value of a time option, parsed as RFC3339 or using one of the layouts
+// (see time.Parse). It returns an ErrInvalidValue error if the value doesn't match any layout.
+func (a Args) GetTimeOption(name string, layouts []string, def time.Time) (time.Time, error) {
+	val, ok := a.Options[name]
+	if !ok {
+		return def, nil
+	}
+
+	for _, layout := range append([]string{time.RFC3339}, layouts...) {
+		if t, err := time.Parse(layout, val); err == nil {
+			return t, nil
+		}
+	}
+
+	return def, fmt.Errorf("%w for %s: %q is not a valid time", ErrInvalidValue, optionFlag(name), val)
+}
@@ -0,0 +1,30 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetTimeOption(test *testing.T) {
+	parsed := ParseArgs(`--at="2024-06-01 14:00" --since=2024-01-02T03:04:05Z --bad=tomorrow`, Concat())
+	layouts := []string{"2006-01-02 15:04", "2006-01-02"}
+	def := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for name, expected := range map[string]time.Time{
+		"at":      time.Date(2024, 6, 1, 14, 0, 0, 0, time.UTC),
+		"since":   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"missing": def,
+	} {
+		t, err := parsed.GetTimeOption(name, layouts, def)
+		if err != nil || !t.Equal(expected) {
+			test.Errorf("%v: expected %v got %v %v", name, expected, t, err)
+		}
+	}
+
+	if _, err := parsed.GetTimeOption("bad", layouts, def); !errors.Is(err, ErrInvalidValue) {
+		test.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package args
+
+import "strings"
+
+// GlobChars returns the byte offsets of the wildcard characters (see GLOB_CHARS) in token
+// that are not quoted or escaped, i.e. the ones a shell would expand.
+// Quoted parts can be concatenated to unquoted ones (i.e. "my dir"/*.go).
+func GlobChars(token string, options ...GetArgsOption) []int {
+	scanner := getScanner(token, options...)
+	scanner.TrackSegments = true
+	scanner.Concat = true
+	scanner.NoBrackets = true
+	scanner.NoSymbols = true
+
+	offsets := []int{}
+
+	for {
+		tok, err := scanner.Next()
+
+		for _, seg := range tok.Segments {
+			if seg.Quote != NO_QUOTE || seg.End > len(token) {
+				continue
+			}
+
+			escape := false
+
+			for i, c := range token[seg.Start:seg.End] {
+				switch {
+				case escape:
+					escape = false
+
+				case scanner.isEscape(c):
+					escape = true
+
+				case strings.ContainsRune(GLOB_CHARS, c):
+					offsets = append(offsets, seg.Start+i)
+				}
+			}
+		}
+
+		if err != nil {
+			return offsets
+		}
+	}
+}
+
+// HasGlob returns true if token contains wildcard characters that are not quoted or escaped (see GlobChars),
+// so that the caller can decide whether to expand it or to use it as is
+func HasGlob(token string, options ...GetArgsOption) bool {
+	return len(GlobChars(token, options...)) > 0
+}
@@ -0,0 +1,32 @@
+package args
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGlobChars(test *testing.T) {
+	tests := []struct {
+		token    string
+		expected []int
+	}{
+		{"*.go", []int{0}},
+		{"file.txt", []int{}},
+		{`"*.go"`, []int{}},
+		{`'a?'`, []int{}},
+		{`\*.go`, []int{}},
+		{`"my dir"/*.go`, []int{9}},
+		{`[abc]?`, []int{0, 5}},
+		{`a\[b]*`, []int{5}},
+	}
+
+	for _, t := range tests {
+		if offsets := GlobChars(t.token); !reflect.DeepEqual(offsets, t.expected) {
+			test.Errorf("%q: expected %v got %v", t.token, t.expected, offsets)
+		}
+
+		if HasGlob(t.token) != (len(t.expected) > 0) {
+			test.Errorf("%q: expected HasGlob %v", t.token, len(t.expected) > 0)
+		}
+	}
+}
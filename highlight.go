@@ -0,0 +1,126 @@
+package args
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SpanKind is the syntactic class of a span of the input (see Highlight)
+type SpanKind int
+
+const (
+	SpanCommand  SpanKind = iota // a command name (first word of a command)
+	SpanArgument                 // a positional argument
+	SpanOption                   // an option (-x, --name, --name=)
+	SpanValue                    // the value of an option (--name=value)
+	SpanString                   // a quoted string
+	SpanOperator                 // a symbol, operator or user token (|, >, &&, ;)
+	SpanComment                  // a comment
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case SpanCommand:
+		return "command"
+	case SpanArgument:
+		return "argument"
+	case SpanOption:
+		return "option"
+	case SpanValue:
+		return "value"
+	case SpanString:
+		return "string"
+	case SpanOperator:
+		return "operator"
+	case SpanComment:
+		return "comment"
+	}
+
+	return "unknown"
+}
+
+// Span is a classified part of the input, from byte offset Start to End (excluded)
+type Span struct {
+	Kind  SpanKind
+	Start int
+	End   int
+}
+
+// Highlight classifies the spans of the input line (command, option, option value, quoted string,
+// operator, comment) for syntax highlighting. The input doesn't need to be complete or valid:
+// an unterminated quote is returned as a string span up to the end of the line.
+// Spaces are not included in any span. Comments are highlighted only if enabled (see Comments).
+func Highlight(line string, options ...GetArgsOption) (spans []Span) {
+	scanner := getScanner(line, options...)
+	scanner.TrackSegments = true
+	scanner.CommentTokens = true
+	scanner.Strict = false
+
+	command := true // the next word is a command
+	flags := true   // options are still recognized (before "--")
+
+	for {
+		tok, err := scanner.Next()
+
+		switch {
+		case len(tok.Segments) == 0:
+			// empty token (before a user token or at the end of the input)
+
+		case tok.Type == CommentToken:
+			spans = append(spans, Span{SpanComment, tok.Segments[0].Start, tok.Segments[0].End})
+
+		case tok.Type == SymbolToken || tok.Type == OperatorToken:
+			seg := tok.Segments[0]
+			spans = append(spans, Span{SpanOperator, seg.Start, seg.End})
+			if isShellSeparator(tok.Value) {
+				command, flags = true, true
+			}
+
+		default:
+			kind := SpanArgument
+			if command {
+				kind = SpanCommand
+			} else if flags && tok.Segments[0].Quote == NO_QUOTE && strings.HasPrefix(tok.Value, "-") && tok.Value != "-" {
+				kind = SpanOption
+				if tok.Value == "--" {
+					flags = false
+				}
+			}
+
+			for _, seg := range tok.Segments {
+				switch {
+				case scanner.isQuote(seg.Quote):
+					spans = append(spans, Span{SpanString, seg.Start, seg.End})
+
+				case kind == SpanOption:
+					if i := strings.IndexByte(line[seg.Start:seg.End], '='); i >= 0 {
+						spans = append(spans, Span{SpanOption, seg.Start, seg.Start + i + 1})
+						if seg.Start+i+1 < seg.End {
+							spans = append(spans, Span{SpanValue, seg.Start + i + 1, seg.End})
+						}
+						kind = SpanValue
+					} else {
+						spans = append(spans, Span{kind, seg.Start, seg.End})
+					}
+
+				default:
+					spans = append(spans, Span{kind, seg.Start, seg.End})
+				}
+			}
+
+			command = false
+		}
+
+		if err != nil {
+			return
+		}
+
+		if tok.End == EndUserToken {
+			end := scanner.offset
+			spans = append(spans, Span{SpanOperator, end - utf8.RuneLen(rune(tok.Delim)), end})
+			if isShellSeparator(string(rune(tok.Delim))) {
+				command, flags = true, true
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package args
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestHighlight(test *testing.T) {
+	line := `grep -i --color=auto "foo bar" *.go | wc -l # count`
+
+	expected := []Span{
+		{SpanCommand, 0, 4},
+		{SpanOption, 5, 7},
+		{SpanOption, 8, 16},
+		{SpanValue, 16, 20},
+		{SpanString, 21, 30},
+		{SpanArgument, 31, 35},
+		{SpanOperator, 36, 37},
+		{SpanCommand, 38, 40},
+		{SpanOption, 41, 43},
+		{SpanComment, 44, 51},
+	}
+
+	if spans := Highlight(line, Comments(WordStartComments)); !reflect.DeepEqual(spans, expected) {
+		test.Errorf("expected %v got %v", expected, spans)
+	}
+}
+
+func TestHighlightUserTokens(test *testing.T) {
+	line := `cd /tmp;ls -- -x 'unterminated`
+
+	expected := []Span{
+		{SpanCommand, 0, 2},
+		{SpanArgument, 3, 7},
+		{SpanOperator, 7, 8},
+		{SpanCommand, 8, 10},
+		{SpanOption, 11, 13},
+		{SpanArgument, 14, 16},
+		{SpanString, 17, 30},
+	}
+
+	if spans := Highlight(line, UserTokens(";")); !reflect.DeepEqual(spans, expected) {
+		test.Errorf("expected %v got %v", expected, spans)
+	}
+}
+
+func ExampleHighlight() {
+	line := `ls --sort=time "my dir"`
+
+	for _, span := range Highlight(line) {
+		fmt.Printf("%-8v %v\n", span.Kind, line[span.Start:span.End])
+	}
+	// Output:
+	// command  ls
+	// option   --sort=
+	// value    time
+	// string   "my dir"
+}
@@ -0,0 +1,70 @@
+package args
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// INIFile maps section names to their key/value pairs.
+// Keys declared before the first section are stored in the "" section.
+type INIFile map[string]map[string]string
+
+// Get returns the value for key in section, and whether the key was set
+func (ini INIFile) Get(section, key string) (string, bool) {
+	value, ok := ini[section][key]
+	return value, ok
+}
+
+// ParseINI parses a simple INI-style file:
+//
+//   - [section] lines start a new section
+//   - key = value lines set a value in the current section
+//   - lines starting with # or ; are comments, and # at the beginning of a word starts an inline comment
+//   - values can be double quoted (with C-style escapes) or single quoted (literal), and unquoted spaces are collapsed
+func ParseINI(r io.Reader) (INIFile, error) {
+	ini := INIFile{}
+	section := ""
+	lines := bufio.NewScanner(r)
+
+	for n := 1; lines.Scan(); n++ {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return ini, wrapDetail(ErrInvalidINI, "line "+strconv.Itoa(n)+": unterminated section")
+			}
+
+			section = strings.TrimSpace(line[1:end])
+			if _, ok := ini[section]; !ok {
+				ini[section] = map[string]string{}
+			}
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			return ini, wrapDetail(ErrInvalidINI, "line "+strconv.Itoa(n)+": expected key = value")
+		}
+
+		values, err := GetArgsStrict(parts[1],
+			Concat(), POSIXQuotes(), CEscapes(), NoSymbols(), NoBrackets(), Comments(WordStartComments))
+		if err != nil {
+			return ini, wrap(err, "line "+strconv.Itoa(n)+": "+err.Error())
+		}
+
+		if _, ok := ini[section]; !ok {
+			ini[section] = map[string]string{}
+		}
+
+		ini[section][key] = strings.Join(values, " ")
+	}
+
+	return ini, lines.Err()
+}
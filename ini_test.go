@@ -0,0 +1,56 @@
+package args
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseINI(test *testing.T) {
+	ini, err := ParseINI(strings.NewReader(`
+; global settings
+name = my app
+
+[server]
+host = localhost  # inline comment
+port=8080
+banner = "Welcome\tto #1"
+
+[paths]
+root = '/var/lib/my app'
+empty =
+`))
+
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	expected := INIFile{
+		"":       {"name": "my app"},
+		"server": {"host": "localhost", "port": "8080", "banner": "Welcome\tto #1"},
+		"paths":  {"root": "/var/lib/my app", "empty": ""},
+	}
+
+	if !reflect.DeepEqual(ini, expected) {
+		test.Errorf("expected %q got %q", expected, ini)
+	}
+
+	if v, ok := ini.Get("server", "port"); !ok || v != "8080" {
+		test.Errorf("expected 8080 got %q %v", v, ok)
+	}
+
+	if _, ok := ini.Get("server", "missing"); ok {
+		test.Errorf("expected missing key")
+	}
+
+	for _, bad := range []string{"[section", "no value", "= value"} {
+		if _, err := ParseINI(strings.NewReader(bad)); !errors.Is(err, ErrInvalidINI) {
+			test.Errorf("%q: expected ErrInvalidINI, got %v", bad, err)
+		}
+	}
+
+	if _, err := ParseINI(strings.NewReader(`key = "unterminated`)); !errors.Is(err, ErrUnterminatedQuote) {
+		test.Errorf("expected ErrUnterminatedQuote, got %v", err)
+	}
+}
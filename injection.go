@@ -0,0 +1,212 @@
+package args
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RiskKind is the kind of construct reported by Risks
+type RiskKind int
+
+const (
+	RiskMetachar     RiskKind = iota // command separator or grouping: ; & | ( ) or newline
+	RiskRedirection                  // input or output redirection: < >
+	RiskSubstitution                 // command or process substitution: $(...), `...`, <(...), >(...)
+	RiskExpansion                    // parameter expansion: $NAME, ${...}, $1, $?
+	RiskMalformed                    // unterminated quote or trailing escape
+	RiskGlob                         // pathname expansion: * ? [...]
+	RiskTilde                        // tilde expansion: ~ or ~user at the beginning of a word
+	RiskBrace                        // brace expansion: {a,b} or {1..9}
+	RiskComment                      // # at the beginning of a word (the rest of the line is ignored)
+)
+
+func (k RiskKind) String() string {
+	switch k {
+	case RiskMetachar:
+		return "metacharacter"
+	case RiskRedirection:
+		return "redirection"
+	case RiskSubstitution:
+		return "substitution"
+	case RiskExpansion:
+		return "expansion"
+	case RiskMalformed:
+		return "malformed"
+	case RiskGlob:
+		return "glob"
+	case RiskTilde:
+		return "tilde"
+	case RiskBrace:
+		return "brace"
+	case RiskComment:
+		return "comment"
+	}
+
+	return "RiskKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Risk is a construct that a shell would interpret, found at the specified byte offset
+type Risk struct {
+	Kind   RiskKind
+	Offset int
+	Text   string // the characters that start the construct
+	Quoted bool   // inside double quotes
+}
+
+func (r Risk) String() string {
+	return strconv.Itoa(r.Offset) + ": " + r.Kind.String() + " " + strconv.Quote(r.Text)
+}
+
+// Risks returns the constructs in line that a POSIX shell would interpret, instead of passing them
+// as literal arguments: unquoted metacharacters, redirections, globs, tildes, braces and comments,
+// substitutions and expansions (also inside double quotes). The line is analyzed according to the POSIX shell rules, not the
+// Scanner options, since it's meant for a real shell. A gateway can reject the line if any are found.
+func Risks(line string) []Risk {
+	risks := []Risk{}
+	quote := NO_QUOTE
+	quotePos := 0
+	escape := false
+	sep := true // the previous character ends a word (for ~ and #)
+
+	add := func(kind RiskKind, offset, size int) {
+		risks = append(risks, Risk{Kind: kind, Offset: offset, Text: line[offset : offset+size], Quoted: quote == '"'})
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		wordStart := sep
+		sep = false
+
+		var next byte
+		if i+1 < len(line) {
+			next = line[i+1]
+		}
+
+		switch {
+		case escape:
+			escape = false
+
+		case quote == '\'':
+			if c == '\'' {
+				quote = NO_QUOTE
+			}
+
+		case c == '\\':
+			escape = true
+
+		case c == '`':
+			add(RiskSubstitution, i, 1)
+			sep = true
+
+		case c == '$' && next == '(':
+			add(RiskSubstitution, i, 2)
+			i++
+			sep = true
+
+		case c == '$' && isNameChar(next):
+			end := i + 2
+			for end < len(line) && isNameChar(line[end]) {
+				end++
+			}
+
+			add(RiskExpansion, i, end-i)
+			i = end - 1
+
+		case c == '$' && next != 0 && strings.IndexByte("{?!#$*@-0123456789", next) >= 0:
+			add(RiskExpansion, i, 2)
+			i++
+
+		case quote == '"':
+			if c == '"' {
+				quote = NO_QUOTE
+			}
+
+		case c == '\'' || c == '"':
+			quote = rune(c)
+			quotePos = i
+
+		case (c == '<' || c == '>') && next == '(':
+			add(RiskSubstitution, i, 2)
+			i++
+			sep = true
+
+		case c == '<' || c == '>':
+			add(RiskRedirection, i, 1)
+			sep = true
+
+		case c == ';' || c == '&' || c == '|' || c == '(' || c == ')' || c == '\n':
+			add(RiskMetachar, i, 1)
+			sep = true
+
+		case c == ' ' || c == '\t':
+			sep = true
+
+		case c == '#' && wordStart:
+			add(RiskComment, i, 1)
+
+		case c == '~' && wordStart:
+			add(RiskTilde, i, 1)
+
+		case c == '*' || c == '?' || c == '[' && strings.IndexByte(wordAt(line, i+1), ']') > 0:
+			add(RiskGlob, i, 1)
+
+		case c == '{' && isBrace(wordAt(line, i+1)):
+			add(RiskBrace, i, 1)
+		}
+	}
+
+	if quote != NO_QUOTE {
+		add(RiskMalformed, quotePos, 1)
+	} else if escape {
+		add(RiskMalformed, len(line)-1, 1)
+	}
+
+	return risks
+}
+
+// IsSafe returns true if line doesn't contain any construct that a shell would interpret (see Risks)
+func IsSafe(line string) bool {
+	return len(Risks(line)) == 0
+}
+
+// wordAt returns the rest of the word starting at offset i (up to a space or metacharacter)
+func wordAt(line string, i int) string {
+	if n := strings.IndexAny(line[i:], " \t\n;&|()<>"); n >= 0 {
+		return line[i : i+n]
+	}
+
+	return line[i:]
+}
+
+// isBrace returns true if word (after the opening brace) is a brace expansion: a list (a,b}
+// or a sequence (1..9}, up to the matching closing brace
+func isBrace(word string) bool {
+	depth := 0
+
+	for i := 0; i < len(word); i++ {
+		switch word[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return false
+			}
+			depth--
+		case ',':
+			if depth == 0 && strings.IndexByte(word[i:], '}') > 0 {
+				return true
+			}
+		case '.':
+			if depth == 0 && strings.HasPrefix(word[i:], "..") && strings.IndexByte(word[i:], '}') > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isNameChar returns true if c can be part of a shell variable name
+func isNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
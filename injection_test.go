@@ -0,0 +1,47 @@
+package args
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRisks(test *testing.T) {
+	tests := []struct {
+		line     string
+		expected []Risk
+	}{
+		{`ls -l "my dir" 'a;b' a\;b`, []Risk{}},
+		{`ls; rm -rf /`, []Risk{{RiskMetachar, 2, ";", false}}},
+		{`cat a && b|c`, []Risk{{RiskMetachar, 6, "&", false}, {RiskMetachar, 7, "&", false}, {RiskMetachar, 10, "|", false}}},
+		{`echo x >out <in`, []Risk{{RiskRedirection, 7, ">", false}, {RiskRedirection, 12, "<", false}}},
+		{`echo "$(id)" $HOME_DIR/x`, []Risk{{RiskSubstitution, 6, "$(", true}, {RiskExpansion, 13, "$HOME_DIR", false}}},
+		{"echo `id` '$(id)' \"${x}\" $1", []Risk{{RiskSubstitution, 5, "`", false}, {RiskSubstitution, 8, "`", false}, {RiskExpansion, 19, "${", true}, {RiskExpansion, 25, "$1", false}}},
+		{`diff <(ls) "a`, []Risk{{RiskSubstitution, 5, "<(", false}, {RiskMetachar, 9, ")", false}, {RiskMalformed, 11, `"`, true}}},
+		{"a\nb c\\", []Risk{{RiskMetachar, 1, "\n", false}, {RiskMalformed, 5, `\`, false}}},
+		{`price $ 5`, []Risk{}},
+		{`rm -rf *`, []Risk{{RiskGlob, 7, "*", false}}},
+		{`ls a?.go [ab].c "*" '?' \* x[1`, []Risk{{RiskGlob, 4, "?", false}, {RiskGlob, 9, "[", false}}},
+		{`cat ~/.ssh/id_rsa ~root a~b --dir=~`, []Risk{{RiskTilde, 4, "~", false}, {RiskTilde, 18, "~", false}}},
+		{`echo {a,b} {1..3} {x} "{a,b}" x{}`, []Risk{{RiskBrace, 5, "{", false}, {RiskBrace, 11, "{", false}}},
+		{`rm -rf / #x a#b "#" $#`, []Risk{{RiskComment, 9, "#", false}, {RiskExpansion, 20, "$#", false}}},
+		{`a;#x`, []Risk{{RiskMetachar, 1, ";", false}, {RiskComment, 2, "#", false}}},
+	}
+
+	for _, t := range tests {
+		if risks := Risks(t.line); !reflect.DeepEqual(risks, t.expected) {
+			test.Errorf("%q: expected %v got %v", t.line, t.expected, risks)
+		}
+
+		if IsSafe(t.line) != (len(t.expected) == 0) {
+			test.Errorf("%q: expected IsSafe %v", t.line, len(t.expected) == 0)
+		}
+	}
+}
+
+func TestIsSafeExpansions(test *testing.T) {
+	for _, line := range []string{"rm -rf *", "cat ~/.ssh/id_rsa", "echo {a,b}", "rm -rf / #x"} {
+		if IsSafe(line) {
+			test.Errorf("%q: expected unsafe", line)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSONBrackets validates bracketed tokens ({...} and [...]) as JSON.
+// Invalid tokens are reported as ErrInvalidJSON (returned as an error in Strict mode, see GetArgsStrict)
+func JSONBrackets() GetArgsOption {
+	return func(s *Scanner) {
+		s.JSONBrackets = true
+	}
+}
+
+// validateJSON reports an ErrInvalidJSON error if the bracketed token at offset is not valid JSON
+func (scanner *Scanner) validateJSON(value string, offset int) error {
+	var v interface{}
+
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		perr := &ParseError{Offset: offset, Err: ErrInvalidJSON, Detail: fmt.Sprintf("(%v)", err)}
+		if serr, ok := err.(*json.SyntaxError); ok && serr.Offset > 0 {
+			perr.Offset += int(serr.Offset) - 1
+		}
+
+		scanner.report(SeverityError, perr)
+	}
+
+	return scanner.strictError()
+}
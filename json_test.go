@@ -0,0 +1,39 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestJSONBrackets(test *testing.T) {
+	args, err := GetArgsStrict(`set config {"a": 1, "b": [1, 2]} (not json)`, JSONBrackets())
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	if expected := []string{"set", "config", `{"a": 1, "b": [1, 2]}`, "(not json)"}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+
+	_, err = GetArgsStrict(`set config {"a": 1,}`, JSONBrackets())
+
+	var perr *ParseError
+	if !errors.As(err, &perr) || !errors.Is(err, ErrInvalidJSON) {
+		test.Fatalf("expected ErrInvalidJSON, got %v", err)
+	}
+
+	if perr.Offset != 19 {
+		test.Errorf("expected offset 19 got %v", perr.Offset)
+	}
+
+	if diags := Validate(`set [1, 2`, JSONBrackets()); len(diags) != 1 || !errors.Is(diags[0].Err, ErrUnbalancedBracket) {
+		test.Errorf("expected unbalanced bracket, got %v", diags)
+	}
+
+	if args := GetArgs(`set {a: 1}`, JSONBrackets()); len(args) != 2 {
+		test.Errorf("expected lenient parsing, got %q", args)
+	}
+}
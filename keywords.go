@@ -0,0 +1,86 @@
+package args
+
+import "strings"
+
+// SHELL_KEYWORDS are the shell reserved words recognized by ShellKeywords
+var SHELL_KEYWORDS = []string{
+	"if", "then", "else", "elif", "fi",
+	"case", "esac", "for", "select", "while", "until", "do", "done",
+	"in", "function", "time", "!",
+}
+
+// SHELL_SEPARATORS are the tokens after which a new command starts
+var SHELL_SEPARATORS = []string{";", "|", "&", "&&", "||", ";;", "(", "\n"}
+
+// ShellKeywords tags the shell reserved words (see SHELL_KEYWORDS) in command position as KeywordToken,
+// so that compound commands (if, for, while, ...) can be detected. A word is in command position
+// at the beginning of the input, after a separator (newline, ;, |, &, &&, ||) or after a keyword.
+// The option also splits words on ";" (as a user token).
+func ShellKeywords() GetArgsOption {
+	return func(s *Scanner) {
+		s.ShellKeywords = true
+
+		if !strings.ContainsRune(s.UserTokens, ';') {
+			s.UserTokens += ";"
+		}
+	}
+}
+
+func isShellKeyword(word string) bool {
+	for _, k := range SHELL_KEYWORDS {
+		if word == k {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isShellSeparator(word string) bool {
+	for _, s := range SHELL_SEPARATORS {
+		if word == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tagKeyword sets the token type to KeywordToken if the token is a shell keyword in command position
+func (scanner *Scanner) tagKeyword(tok *Token) {
+	cmdPos := !scanner.argPos
+
+	switch {
+	case tok.Type == CommentToken:
+		if tok.Delim == '\n' {
+			scanner.argPos = false
+		}
+		return
+
+	case tok.Type != WordToken:
+		// a separator starts a new command
+		scanner.argPos = !isShellSeparator(tok.Value)
+		return
+
+	case cmdPos && !tok.Quoted && isShellKeyword(tok.Value):
+		tok.Type = KeywordToken
+		scanner.argPos = tok.Value == "for" || tok.Value == "select" || tok.Value == "case"
+
+	case tok.Value == "" && !tok.Quoted:
+		// empty token before a user token
+
+	default:
+		scanner.argPos = true
+	}
+
+	if tok.End == EndComment && !scanner.CommentTokens {
+		// the comment was skipped, up to the newline
+		scanner.argPos = false
+	}
+
+	if tok.End == EndUserToken || tok.End == EndSpace {
+		if isShellSeparator(string(rune(tok.Delim))) {
+			scanner.argPos = false
+		}
+	}
+}
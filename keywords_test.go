@@ -0,0 +1,61 @@
+package args
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShellKeywords(test *testing.T) {
+	scanner := NewScannerString("if test -f x; then echo if done; fi\nfor i in a b; do echo \"done\" | grep done; done\nwhile true\ndo time ls; done")
+	ShellKeywords()(scanner)
+
+	keywords := []string{}
+	for {
+		tok, err := scanner.Next()
+		if err != nil {
+			break
+		}
+
+		if tok.Type == KeywordToken {
+			keywords = append(keywords, tok.Value)
+		}
+	}
+
+	expected := []string{"if", "then", "fi", "for", "do", "done", "while", "do", "time", "done"}
+	if !reflect.DeepEqual(keywords, expected) {
+		test.Errorf("expected %q got %q", expected, keywords)
+	}
+
+	if args := GetArgs("if true; then x; fi", ShellKeywords()); !reflect.DeepEqual(args, []string{"if", "true", ";", "then", "x", ";", "fi"}) {
+		test.Errorf("unexpected args %q", args)
+	}
+}
+
+func TestShellKeywordsAfterComment(test *testing.T) {
+	for _, opts := range [][]GetArgsOption{
+		{Comments(WordStartComments)},
+		{Comments(WordStartComments), CommentTokens()},
+		{Comments(AnywhereComments)},
+	} {
+		for _, line := range []string{"echo x # c\nif true; then y; fi", "echo x#c\nif true; then y; fi"} {
+			scanner := getScanner(line, append(opts, ShellKeywords())...)
+
+			keywords := []string{}
+			for {
+				tok, err := scanner.Next()
+				if err != nil {
+					break
+				}
+
+				if tok.Type == KeywordToken {
+					keywords = append(keywords, tok.Value)
+				}
+			}
+
+			expected := []string{"if", "then", "fi"}
+			if !reflect.DeepEqual(keywords, expected) {
+				test.Errorf("%q: expected %q got %q", line, expected, keywords)
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package args
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const GLOB_CHARS = "*?["
+
+// Lint walks the input line and returns the problems found (see Validate)
+// plus warnings for likely mistakes:
+//   - unquoted glob patterns
+//   - escaped white space at the end of the line (stray trailing backslash)
+//   - mismatched quote styles ("text')
+//   - options after --
+func Lint(line string, options ...GetArgsOption) []Diagnostic {
+	scanner := getScanner(line, options...)
+	scanner.TrackSegments = true
+
+	tokens := []Token{}
+
+	for {
+		tok, err := scanner.Next()
+		if err != nil {
+			if err != io.EOF {
+				scanner.report(SeverityError, err)
+			}
+			break
+		}
+
+		tokens = append(tokens, tok)
+	}
+
+	endOptions := false
+
+	for _, tok := range tokens {
+		if tok.Type != WordToken {
+			continue
+		}
+
+		if endOptions && len(tok.Segments) > 0 && tok.Segments[0].Quote == NO_QUOTE && strings.HasPrefix(tok.Value, "-") {
+			scanner.warn(tok.Segments[0].Start, "option "+strconv.Quote(tok.Value)+" after --")
+		}
+
+		if tok.Value == "--" && !tok.Quoted {
+			endOptions = true
+		}
+
+		for _, seg := range tok.Segments {
+			if seg.Quote != NO_QUOTE || seg.End > len(line) {
+				continue
+			}
+
+			if p := scanner.findUnescaped(line[seg.Start:seg.End], GLOB_CHARS); p >= 0 {
+				scanner.warn(seg.Start+p, "unquoted glob pattern "+strconv.Quote(tok.Value))
+			}
+		}
+	}
+
+	//
+	// escaped white space at the end of the line
+	//
+	if trimmed := strings.TrimRightFunc(line, unicode.IsSpace); len(trimmed) < len(line) {
+		n := 0 // number of escape characters before the trailing spaces
+		for i := len(trimmed) - 1; i >= 0 && scanner.isEscape(rune(trimmed[i])); i-- {
+			n++
+		}
+
+		if n%2 == 1 {
+			scanner.warn(len(trimmed)-1, "escaped white space at the end of the line")
+		}
+	}
+
+	//
+	// mismatched quote styles
+	//
+	for _, d := range scanner.Diagnostics() {
+		if !errors.Is(d.Err, ErrUnterminatedQuote) || d.Offset >= len(line) {
+			continue
+		}
+
+		open := line[d.Offset]
+		for i := d.Offset + 1; i < len(line); i++ {
+			if c := line[i]; c != open && strings.IndexByte(QUOTE_CHARS, c) >= 0 &&
+				(i == len(line)-1 || scanner.isSpace(rune(line[i+1]))) {
+				scanner.warn(i, "quote "+strconv.QuoteRune(rune(open))+" closed with "+strconv.QuoteRune(rune(c)))
+				break
+			}
+		}
+	}
+
+	if !scanner.SmartQuotes && strings.ContainsAny(line, "“”‘’") {
+		scanner.warn(strings.IndexAny(line, "“”‘’"), "typographic quotes are not quote characters (see SmartQuotes)")
+	}
+
+	return scanner.Diagnostics()
+}
+
+// warn records a warning
+func (scanner *Scanner) warn(offset int, message string) {
+	scanner.diagnostics = append(scanner.diagnostics, Diagnostic{Offset: offset, Severity: SeverityWarning, Message: message})
+}
+
+// findUnescaped returns the offset of the first character in chars that is not escaped, or -1
+func (scanner *Scanner) findUnescaped(s string, chars string) int {
+	escape := false
+
+	for i, c := range s {
+		switch {
+		case escape:
+			escape = false
+
+		case scanner.isEscape(c):
+			escape = true
+
+		case strings.ContainsRune(chars, c):
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,29 @@
+package args
+
+import (
+	"testing"
+)
+
+func TestLint(test *testing.T) {
+	for line, expected := range map[string][]string{
+		`ls *.go "*.txt" \*.md`:         {"unquoted glob pattern \"*.go\""},
+		`rm -- -f file`:                 {"option \"-f\" after --"},
+		"echo dir\\ ":                   {"escaped white space at the end of the line"},
+		`echo "hello' world'`:           {"unterminated quote", "quote '\"' closed with '\\''"},
+		"say \u201Chello\u201D":         {"typographic quotes are not quote characters (see SmartQuotes)"},
+		`cp "a b" c -- d {"x": [1, 2]}`: nil,
+	} {
+		diags := Lint(line)
+
+		if len(diags) != len(expected) {
+			test.Errorf("%v: expected %q got %v", line, expected, diags)
+			continue
+		}
+
+		for i, d := range diags {
+			if d.Message != expected[i] {
+				test.Errorf("%v: expected %q got %q", line, expected[i], d.Message)
+			}
+		}
+	}
+}
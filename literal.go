@@ -0,0 +1,199 @@
+package args
+
+import (
+	"io"
+	"strconv"
+)
+
+// literalParser parses structured literals, using a Scanner to read scalar values
+type literalParser struct {
+	scanner *Scanner
+}
+
+// ParseLiteral decodes a token into a Go value:
+//
+//   - {key: value, ...} (or {key=value, ...}) is decoded as map[string]interface{}
+//   - [value, ...] is decoded as []interface{}
+//   - integers are decoded as int (or float64 if they don't fit), other numbers as float64
+//   - true, false and null are decoded as bool and nil
+//   - quoted strings (using the same quoting and escaping rules of GetArgs) and other words are decoded as string
+//
+// JSON is a valid literal, but keys and string values don't need to be quoted.
+func ParseLiteral(token string) (interface{}, error) {
+	p := &literalParser{scanner: NewScannerString(token)}
+	p.scanner.NoBrackets = true
+	p.scanner.NoSymbols = true
+	p.scanner.Concat = true
+	p.scanner.Strict = true
+
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+
+	if c, err := p.peek(); err != io.EOF {
+		return nil, p.error("unexpected " + strconv.QuoteRune(c) + " after value")
+	}
+
+	return v, nil
+}
+
+func (p *literalParser) error(detail string) error {
+	return &ParseError{Offset: p.scanner.offset, Err: ErrInvalidLiteral, Detail: detail}
+}
+
+// peek skips spaces and returns the next rune, without consuming it
+func (p *literalParser) peek() (rune, error) {
+	for {
+		c, _, err := p.scanner.readRune()
+		if err != nil {
+			return c, err
+		}
+
+		if !p.scanner.isSpace(c) {
+			p.scanner.unreadRune()
+			return c, nil
+		}
+	}
+}
+
+// expect consumes the next rune, if it is one of the expected runes
+func (p *literalParser) expect(expected ...rune) (rune, bool) {
+	c, err := p.peek()
+	if err != nil {
+		return c, false
+	}
+
+	for _, e := range expected {
+		if c == e {
+			p.scanner.readRune()
+			return c, true
+		}
+	}
+
+	return c, false
+}
+
+func (p *literalParser) value() (interface{}, error) {
+	c, err := p.peek()
+	if err == io.EOF {
+		return nil, p.error("missing value")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch c {
+	case '{':
+		return p.object()
+	case '[':
+		return p.array()
+	}
+
+	tok, err := p.word(",]}")
+	if err != nil {
+		return nil, err
+	}
+
+	if tok.Quoted {
+		return tok.Value, nil
+	}
+
+	switch tok.Value {
+	case "":
+		return nil, p.error("missing value")
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	if i, err := strconv.Atoi(tok.Value); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(tok.Value, 64); err == nil {
+		return f, nil
+	}
+
+	return tok.Value, nil
+}
+
+// word returns the next word, terminated by spaces or by one of the delimiters (that is not consumed)
+func (p *literalParser) word(delims string) (Token, error) {
+	p.scanner.UserTokens = delims
+
+	tok, err := p.scanner.Next()
+	if err != nil && err != io.EOF {
+		return tok, err
+	}
+
+	if tok.End == EndUserToken {
+		p.scanner.unreadRune()
+	}
+
+	return tok, nil
+}
+
+func (p *literalParser) object() (interface{}, error) {
+	p.scanner.readRune() // {
+
+	m := map[string]interface{}{}
+
+	if _, ok := p.expect('}'); ok {
+		return m, nil
+	}
+
+	for {
+		key, err := p.word(",:=]}")
+		if err != nil {
+			return nil, err
+		}
+		if key.Value == "" && !key.Quoted {
+			return nil, p.error("missing key")
+		}
+
+		if c, ok := p.expect(':', '='); !ok {
+			return nil, p.error("expected ':' after key " + strconv.Quote(key.Value) + ", got " + strconv.QuoteRune(c))
+		}
+
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		m[key.Value] = v
+
+		if c, ok := p.expect(',', '}'); !ok {
+			return nil, p.error("expected ',' or '}', got " + strconv.QuoteRune(c))
+		} else if c == '}' {
+			return m, nil
+		}
+	}
+}
+
+func (p *literalParser) array() (interface{}, error) {
+	p.scanner.readRune() // [
+
+	a := []interface{}{}
+
+	if _, ok := p.expect(']'); ok {
+		return a, nil
+	}
+
+	for {
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		a = append(a, v)
+
+		if c, ok := p.expect(',', ']'); !ok {
+			return nil, p.error("expected ',' or ']', got " + strconv.QuoteRune(c))
+		} else if c == ']' {
+			return a, nil
+		}
+	}
+}
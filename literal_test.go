@@ -0,0 +1,63 @@
+package args
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseLiteral(test *testing.T) {
+	for token, expected := range map[string]interface{}{
+		`42`:            42,
+		`-1.5`:          -1.5,
+		`true`:          true,
+		`null`:          nil,
+		`hello`:         "hello",
+		`"42"`:          "42",
+		`'it\'s'`:       "it's",
+		`[]`:            []interface{}{},
+		`{}`:            map[string]interface{}{},
+		`[1, two, "3"]`: []interface{}{1, "two", "3"},
+		`{"a": 1, "b": [true, null], "c": {"d": "e"}}`: map[string]interface{}{
+			"a": 1, "b": []interface{}{true, nil}, "c": map[string]interface{}{"d": "e"},
+		},
+		`{name=joe, 'full name': "Joe Smith", url: http://example.com}`: map[string]interface{}{
+			"name": "joe", "full name": "Joe Smith", "url": "http://example.com",
+		},
+	} {
+		v, err := ParseLiteral(token)
+		if err != nil {
+			test.Errorf("%v: unexpected error %v", token, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(v, expected) {
+			test.Errorf("%v: expected %#v got %#v", token, expected, v)
+		}
+	}
+
+	for token, expected := range map[string]error{
+		``:           ErrInvalidLiteral,
+		`[1, 2`:      ErrInvalidLiteral,
+		`[1,,2]`:     ErrInvalidLiteral,
+		`{a 1}`:      ErrInvalidLiteral,
+		`{a: 1} x`:   ErrInvalidLiteral,
+		`{a: "b}`:    ErrUnterminatedQuote,
+		`{: 1}`:      ErrInvalidLiteral,
+		`[1] [2]`:    ErrInvalidLiteral,
+		`{"a": [1}}`: ErrInvalidLiteral,
+	} {
+		if _, err := ParseLiteral(token); !errors.Is(err, expected) {
+			test.Errorf("%v: expected %v got %v", token, expected, err)
+		}
+	}
+}
+
+func ExampleParseLiteral() {
+	args := GetArgs(`set config {retries: 3, hosts: [a, "b c"]}`)
+	v, _ := ParseLiteral(args[2])
+	fmt.Println(v)
+	// Output:
+	// map[hosts:[a b c] retries:3]
+}
@@ -0,0 +1,140 @@
+package args
+
+import (
+	"io"
+	"strconv"
+)
+
+const (
+	// MACRO_DEF is the command that defines a macro (":def name value...")
+	MACRO_DEF = ":def"
+
+	// MAX_MACRO_DEPTH is the default maximum level of nested macro expansions
+	MAX_MACRO_DEPTH = 10
+)
+
+// Macros is a set of named macros, expanded in the token stream.
+// Definitions are expanded when the macro is used, so a macro can refer to macros defined later.
+// The zero value is an empty set, ready to use.
+type Macros struct {
+	MaxDepth int // maximum level of nested expansions (0 means MAX_MACRO_DEPTH)
+
+	defs map[string][]Token
+}
+
+// Define defines (or redefines) a macro
+func (m *Macros) Define(name string, value ...string) {
+	tokens := make([]Token, len(value))
+	for i, v := range value {
+		tokens[i] = Token{Value: v}
+	}
+
+	m.define(name, tokens)
+}
+
+func (m *Macros) define(name string, value []Token) {
+	if m.defs == nil {
+		m.defs = map[string][]Token{}
+	}
+
+	m.defs[name] = value
+}
+
+// Undefine removes a macro
+func (m *Macros) Undefine(name string) {
+	delete(m.defs, name)
+}
+
+// Lookup returns the definition of a macro
+func (m *Macros) Lookup(name string) ([]string, bool) {
+	tokens, ok := m.defs[name]
+	if !ok {
+		return nil, false
+	}
+
+	value := make([]string, len(tokens))
+	for i, t := range tokens {
+		value[i] = t.Value
+	}
+
+	return value, true
+}
+
+// GetArgs splits the line (as GetArgs) and expands the macros. Quoted tokens and user token delimiters are not expanded.
+// If the line is a macro definition (":def name value...") the macro is defined and no arguments are returned.
+func (m *Macros) GetArgs(line string, options ...GetArgsOption) ([]string, error) {
+	scanner := getScanner(line, options...)
+
+	var tokens []Token
+
+	for {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if scanner.keepToken(tok) {
+			tokens = append(tokens, tok)
+		}
+
+		if tok.End == EndUserToken {
+			// the delimiter is an argument, as in GetArgs (but it's never expanded)
+			tokens = append(tokens, Token{Value: string(rune(tok.Delim)), Type: SymbolToken, End: EndUserToken})
+		}
+	}
+
+	return m.process(tokens)
+}
+
+// Expand expands the macros in args (or defines a macro, if args is a macro definition)
+func (m *Macros) Expand(args []string) ([]string, error) {
+	tokens := make([]Token, len(args))
+	for i, a := range args {
+		tokens[i] = Token{Value: a}
+	}
+
+	return m.process(tokens)
+}
+
+func (m *Macros) process(tokens []Token) ([]string, error) {
+	if len(tokens) > 0 && tokens[0].Value == MACRO_DEF && !tokens[0].Quoted {
+		if len(tokens) < 2 {
+			return nil, wrapDetail(ErrInvalidMacro, "missing macro name")
+		}
+
+		m.define(tokens[1].Value, tokens[2:])
+		return nil, nil
+	}
+
+	args := []string{}
+	err := m.expand(tokens, 0, &args)
+	return args, err
+}
+
+func (m *Macros) expand(tokens []Token, depth int, args *[]string) error {
+	max := m.MaxDepth
+	if max <= 0 {
+		max = MAX_MACRO_DEPTH
+	}
+
+	for _, t := range tokens {
+		value, ok := m.defs[t.Value]
+		if !ok || t.Quoted || t.End == EndUserToken && t.Type == SymbolToken {
+			*args = append(*args, t.Value)
+			continue
+		}
+
+		if depth >= max {
+			return wrapDetail(ErrMacroRecursion, strconv.Quote(t.Value)+" expands more than "+strconv.Itoa(max)+" levels")
+		}
+
+		if err := m.expand(value, depth+1, args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
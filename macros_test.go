@@ -0,0 +1,91 @@
+package args
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMacros(test *testing.T) {
+	var m Macros
+
+	for _, c := range []struct {
+		line     string
+		expected []string
+	}{
+		{`:def host "my server" 8080`, nil},
+		{`:def connect ssh host`, nil},
+		{`connect -v`, []string{"ssh", "my server", "8080", "-v"}},
+		{`echo "host" host`, []string{"echo", "host", "my server", "8080"}},
+		{`:def host localhost`, nil},
+		{`connect`, []string{"ssh", "localhost"}},
+		{`":def" x y`, []string{":def", "x", "y"}},
+	} {
+		args, err := m.GetArgs(c.line)
+		if err != nil {
+			test.Errorf("%v: unexpected error %v", c.line, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(args, c.expected) {
+			test.Errorf("%v: expected %q got %q", c.line, c.expected, args)
+		}
+	}
+
+	if value, ok := m.Lookup("connect"); !ok || !reflect.DeepEqual(value, []string{"ssh", "host"}) {
+		test.Errorf("unexpected definition %q", value)
+	}
+
+	m.Undefine("connect")
+	if args, _ := m.Expand([]string{"connect"}); !reflect.DeepEqual(args, []string{"connect"}) {
+		test.Errorf("expected no expansion, got %q", args)
+	}
+
+	m.Define("loop", "a", "loop")
+	if _, err := m.Expand([]string{"loop"}); !errors.Is(err, ErrMacroRecursion) {
+		test.Errorf("expected ErrMacroRecursion, got %v", err)
+	}
+
+	if _, err := m.Expand([]string{":def"}); !errors.Is(err, ErrInvalidMacro) {
+		test.Errorf("expected ErrInvalidMacro, got %v", err)
+	}
+}
+
+func TestMacrosUserTokens(test *testing.T) {
+	var m Macros
+
+	opts := []GetArgsOption{UserTokens(";")}
+	m.Define(";", "x") // delimiters are not expanded
+
+	for _, c := range []struct {
+		line     string
+		expected []string
+	}{
+		{`:def ll ls -l`, nil},
+		{`ll a;ll b`, []string{"ls", "-l", "a", ";", "ls", "-l", "b"}},
+		{`cd /tmp ; ll`, []string{"cd", "/tmp", "", ";", "ls", "-l"}},
+		{`:def both ll a;ll b`, nil},
+		{`both`, []string{"ls", "-l", "a", ";", "ls", "-l", "b"}},
+	} {
+		args, err := m.GetArgs(c.line, opts...)
+		if err != nil {
+			test.Errorf("%v: unexpected error %v", c.line, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(args, c.expected) {
+			test.Errorf("%v: expected %q got %q", c.line, c.expected, args)
+		}
+	}
+}
+
+func ExampleMacros() {
+	var m Macros
+
+	m.GetArgs(`:def ll ls -l`)
+	args, _ := m.GetArgs(`ll /tmp`)
+	fmt.Println(args)
+	// Output:
+	// [ls -l /tmp]
+}
@@ -0,0 +1,78 @@
+package args
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// MAKE_PREFIXES are the special prefixes of a Makefile recipe line ("@" silent, "-" ignore errors, "+" always execute)
+const MAKE_PREFIXES = "@-+"
+
+// MakeRecipe sets the scanner options to parse Makefile recipes (and Procfile commands):
+// POSIX shell quoting, $$ as an escaped $ and backslash-newline-tab continuation lines.
+func MakeRecipe() GetArgsOption {
+	return func(s *Scanner) {
+		s.MakeEscapes = true
+		s.Concat = true
+		s.POSIXQuotes = true
+		s.QuotedNewline = JoinNewline
+		s.Comments = WordStartComments
+		s.Operators = OPERATORS
+	}
+}
+
+// ParseRecipe parses a Makefile recipe (a line starting with a tab, possibly with continuation lines)
+// returning the recipe prefixes (see MAKE_PREFIXES) and the command arguments.
+// Make variables ($(VAR), ${VAR}) are left intact.
+func ParseRecipe(recipe string) (prefixes string, args []string, err error) {
+	recipe = strings.TrimPrefix(recipe, "\t")
+
+	rest := strings.TrimLeft(recipe, MAKE_PREFIXES+" ")
+	prefixes = strings.Replace(recipe[:len(recipe)-len(rest)], " ", "", -1)
+
+	scanner := getScanner(rest, MakeRecipe())
+	args = []string{}
+
+	for {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			return prefixes, args, nil
+		}
+		if err != nil {
+			return prefixes, nil, err
+		}
+
+		args = append(args, tok.Value)
+	}
+}
+
+// ProcfileEntry is a process type declared in a Procfile
+type ProcfileEntry struct {
+	Name string
+	Args []string
+}
+
+// ParseProcfile parses a Procfile (lines in the form "name: command"), returning the process types
+// in order of declaration. Empty lines and comments are skipped.
+func ParseProcfile(r io.Reader) ([]ProcfileEntry, error) {
+	entries := []ProcfileEntry{}
+	lines := bufio.NewScanner(r)
+
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		args := GetArgs(parts[1], MakeRecipe())
+		entries = append(entries, ProcfileEntry{Name: strings.TrimSpace(parts[0]), Args: args})
+	}
+
+	return entries, lines.Err()
+}
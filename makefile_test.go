@@ -0,0 +1,44 @@
+package args
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRecipe(test *testing.T) {
+	prefixes, args, err := ParseRecipe("\t@-echo \"cost: $$5\" $(CC) \\\n\t--flag 'a b'")
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	if prefixes != "@-" {
+		test.Errorf("unexpected prefixes %q", prefixes)
+	}
+
+	if expected := []string{"echo", "cost: $5", "$(CC)", "--flag", "a b"}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+}
+
+func TestParseProcfile(test *testing.T) {
+	procfile := `
+# processes
+web: bundle exec rails server -p $PORT
+worker:  env QUEUE="a b" rake jobs:work
+`
+
+	entries, err := ParseProcfile(strings.NewReader(procfile))
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	expected := []ProcfileEntry{
+		{"web", []string{"bundle", "exec", "rails", "server", "-p", "$PORT"}},
+		{"worker", []string{"env", "QUEUE=a b", "rake", "jobs:work"}},
+	}
+
+	if !reflect.DeepEqual(entries, expected) {
+		test.Errorf("expected %q got %q", expected, entries)
+	}
+}
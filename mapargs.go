@@ -0,0 +1,112 @@
+package args
+
+import (
+	"sort"
+	"strconv"
+)
+
+// MapToArgs converts a map of option values (i.e. a decoded JSON or YAML configuration)
+// into a flag-style list of arguments, that can be parsed back by ParseArgs:
+//
+//   - true booleans become --name (false booleans and nil values are omitted)
+//   - numbers and strings become --name=value
+//   - lists become repeated options (--name=v1 --name=v2)
+//   - nested maps become dotted names (--parent.name=value)
+//   - single character names use a single dash (-v)
+//
+// Options listed in order come first, in the specified order, then all the others in alphabetical order.
+// Use Join to convert the result into a (quoted) command line.
+func MapToArgs(m map[string]interface{}, order ...string) []string {
+	args := []string{}
+	seen := map[string]bool{}
+
+	for _, k := range order {
+		if v, ok := m[k]; ok && !seen[k] {
+			args = appendMapArg(args, k, v)
+			seen[k] = true
+		}
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		args = appendMapArg(args, k, m[k])
+	}
+
+	return args
+}
+
+func appendMapArg(args []string, name string, value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return args
+
+	case bool:
+		if v {
+			args = append(args, optionFlag(name))
+		}
+		return args
+
+	case string:
+		return append(args, optionFlag(name)+"="+v)
+
+	case int:
+		return append(args, optionFlag(name)+"="+strconv.Itoa(v))
+
+	case int64:
+		return append(args, optionFlag(name)+"="+strconv.FormatInt(v, 10))
+
+	case int32:
+		return append(args, optionFlag(name)+"="+strconv.FormatInt(int64(v), 10))
+
+	case uint:
+		return append(args, optionFlag(name)+"="+strconv.FormatUint(uint64(v), 10))
+
+	case uint64:
+		return append(args, optionFlag(name)+"="+strconv.FormatUint(v, 10))
+
+	case uint32:
+		return append(args, optionFlag(name)+"="+strconv.FormatUint(uint64(v), 10))
+
+	case float64:
+		return append(args, optionFlag(name)+"="+strconv.FormatFloat(v, 'f', -1, 64))
+
+	case float32:
+		return append(args, optionFlag(name)+"="+strconv.FormatFloat(float64(v), 'f', -1, 32))
+
+	case []interface{}:
+		for _, item := range v {
+			args = appendMapArg(args, name, item)
+		}
+		return args
+
+	case []string:
+		for _, item := range v {
+			args = appendMapArg(args, name, item)
+		}
+		return args
+
+	case map[string]interface{}:
+		for _, arg := range MapToArgs(v) {
+			// --child=value -> --name.child=value
+			args = append(args, optionFlag(name)+"."+trimDashes(arg))
+		}
+		return args
+	}
+
+	return append(args, optionFlag(name)+"="+sprint(value))
+}
+
+func trimDashes(s string) string {
+	for len(s) > 0 && s[0] == '-' {
+		s = s[1:]
+	}
+
+	return s
+}
@@ -0,0 +1,32 @@
+package args
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMapToArgs(test *testing.T) {
+	var config map[string]interface{}
+
+	if err := json.Unmarshal([]byte(`{"number": 42, "verbose": true, "quiet": false, "name": "my name",
+		"tags": ["a", "b"], "v": true, "ratio": 0.5, "db": {"host": "localhost", "port": 5432}, "none": null}`), &config); err != nil {
+		test.Fatal(err)
+	}
+
+	args := MapToArgs(config, "verbose", "name")
+	if s := fmt.Sprintf("%q", args); s != `["--verbose" "--name=my name" "--db.host=localhost" "--db.port=5432" "--number=42" "--ratio=0.5" "--tags=a" "--tags=b" "-v"]` {
+		test.Errorf("unexpected arguments %v", s)
+	}
+
+	parsed := ParseArgs(Join(args))
+	if parsed.GetOption("name", "") != "my name" || parsed.GetIntOption("number", 0) != 42 || !parsed.GetBoolOption("verbose", false) {
+		test.Errorf("unexpected parsed options %v", parsed.Options)
+	}
+
+	args = MapToArgs(map[string]interface{}{"a": int64(-1), "b": uint32(2), "c": "x", "d": float32(1.5), "e": []string{"y"}})
+	if expected := []string{"-a=-1", "-b=2", "-c=x", "-d=1.5", "-e=y"}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+}
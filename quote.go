@@ -0,0 +1,121 @@
+package args
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// SAFE_CHARS are the (non alphanumeric) characters that don't require quoting
+const SAFE_CHARS = "-_./:=@%+,"
+
+// Quote returns the argument quoted (if needed) so that it is parsed back as a single argument,
+// both by this package and by POSIX shells (i.e. "hello world" or "say \"hi\"")
+func Quote(arg string) string {
+	if arg == "" {
+		return `""`
+	}
+
+	safe := true
+	for _, c := range arg {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune(SAFE_CHARS, c)) {
+			safe = false
+			break
+		}
+	}
+
+	if safe {
+		return arg
+	}
+
+	var b strings.Builder
+
+	b.WriteByte('"')
+	for _, c := range arg {
+		if strings.ContainsRune("\\\"$`", c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// Join returns the list of arguments as a command line, quoting each argument if needed
+func Join(args []string) string {
+	quoted := make([]string, len(args))
+
+	for i, arg := range args {
+		quoted[i] = Quote(arg)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// escapeSpecial escapes (with a backslash) the characters that would otherwise split or change the argument
+func escapeSpecial(arg string) string {
+	var b strings.Builder
+
+	for _, c := range arg {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80 && !unicode.IsSpace(c) || strings.ContainsRune(SAFE_CHARS, c)) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// TokenWriter writes a stream of arguments to an io.Writer, quoted as needed (see Quote) and separated by spaces.
+// It's the streaming counterpart of Join, for very long command lines. The output is buffered: call Flush when done.
+type TokenWriter struct {
+	w     *bufio.Writer
+	first bool
+	err   error
+}
+
+// NewTokenWriter returns a TokenWriter that writes to w
+func NewTokenWriter(w io.Writer) *TokenWriter {
+	return &TokenWriter{w: bufio.NewWriter(w), first: true}
+}
+
+// WriteToken writes an argument (preceded by a space, if it's not the first one).
+// After an error, all following calls return the same error.
+func (tw *TokenWriter) WriteToken(arg string) error {
+	if tw.err != nil {
+		return tw.err
+	}
+
+	if !tw.first {
+		tw.err = tw.w.WriteByte(' ')
+	}
+
+	if tw.err == nil {
+		_, tw.err = tw.w.WriteString(Quote(arg))
+	}
+
+	tw.first = false
+	return tw.err
+}
+
+// WriteTokens writes a list of arguments (see WriteToken)
+func (tw *TokenWriter) WriteTokens(args []string) error {
+	for _, arg := range args {
+		if err := tw.WriteToken(arg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+func (tw *TokenWriter) Flush() error {
+	if tw.err != nil {
+		return tw.err
+	}
+
+	tw.err = tw.w.Flush()
+	return tw.err
+}
@@ -0,0 +1,64 @@
+package args
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQuote(test *testing.T) {
+	args := []string{"simple", "", "with space", `"quoted"`, "it's", `back\slash`, "$HOME", "--opt=a,b", "{x}", "tab\there"}
+
+	line := Join(args)
+	if line != `simple "" "with space" "\"quoted\"" "it's" "back\\slash" "\$HOME" --opt=a,b "{x}" "tab	here"` {
+		test.Errorf("unexpected line %v", line)
+	}
+
+	if parsed := GetArgs(line); !reflect.DeepEqual(parsed, args) {
+		test.Errorf("expected %q got %q", args, parsed)
+	}
+}
+
+func TestTokenWriter(test *testing.T) {
+	args := []string{"echo", "hello world", "", "$HOME"}
+
+	var sb strings.Builder
+	tw := NewTokenWriter(&sb)
+
+	if err := tw.WriteTokens(args); err != nil {
+		test.Fatal(err)
+	}
+	if err := tw.Flush(); err != nil {
+		test.Fatal(err)
+	}
+
+	if line, expected := sb.String(), Join(args); line != expected {
+		test.Errorf("expected %v got %v", expected, line)
+	}
+
+	tw = NewTokenWriter(failingWriter{})
+	tw.WriteToken("x")
+	if err := tw.Flush(); err == nil || tw.WriteToken("y") != err {
+		test.Errorf("expected sticky error, got %v", err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func ExampleTokenWriter() {
+	tw := NewTokenWriter(os.Stdout)
+
+	for _, f := range []string{"a.txt", "my file.txt"} {
+		tw.WriteToken(f)
+	}
+
+	tw.Flush()
+	// Output:
+	// a.txt "my file.txt"
+}
@@ -0,0 +1,92 @@
+package args
+
+import (
+	"path"
+	"strings"
+)
+
+// REDACTED is the default replacement for sensitive values
+const REDACTED = "REDACTED"
+
+// SENSITIVE_OPTIONS are the option name patterns redacted by default (see Redactor)
+var SENSITIVE_OPTIONS = []string{"password", "passwd", "pass", "secret", "*-secret", "token", "*-token", "api-key", "apikey"}
+
+// Redactor masks the values of sensitive options (i.e. --password=secret or --password secret),
+// so that command lines can be logged safely
+type Redactor struct {
+	Names []string // option name patterns, without dashes (see path.Match), i.e. "password" or "*-token"
+	Mask  string   // replacement for the values (REDACTED if empty)
+}
+
+// NewRedactor returns a Redactor for the given option name patterns (SENSITIVE_OPTIONS if none)
+func NewRedactor(names ...string) *Redactor {
+	if len(names) == 0 {
+		names = SENSITIVE_OPTIONS
+	}
+
+	return &Redactor{Names: names}
+}
+
+// IsSensitive returns true if the option name (with or without dashes) matches one of the patterns
+func (r *Redactor) IsSensitive(name string) bool {
+	name = strings.ToLower(strings.TrimLeft(name, "-"))
+
+	for _, pattern := range r.Names {
+		if ok, _ := path.Match(strings.ToLower(strings.TrimLeft(pattern, "-")), name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Redactor) mask() string {
+	if r.Mask == "" {
+		return REDACTED
+	}
+
+	return r.Mask
+}
+
+// Redact returns a copy of args with the values of sensitive options masked.
+// The value is either attached (--password=secret) or the following argument (--password secret),
+// that is masked even if it starts with "-" (a sensitive option with no value is not expected).
+func (r *Redactor) Redact(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+
+	for i := 0; i < len(redacted); i++ {
+		arg := redacted[i]
+
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		parts := strings.SplitN(arg, "=", 2)
+		if !r.IsSensitive(parts[0]) {
+			continue
+		}
+
+		if len(parts) == 2 {
+			redacted[i] = parts[0] + "=" + r.mask()
+		} else if i+1 < len(redacted) && redacted[i+1] != "--" {
+			i++
+			redacted[i] = r.mask()
+		}
+	}
+
+	return redacted
+}
+
+// Join returns the command line for args (see Join), with the values of sensitive options masked
+func (r *Redactor) Join(args []string) string {
+	return Join(r.Redact(args))
+}
+
+// RedactLine splits the line and returns it with the values of sensitive options masked
+func (r *Redactor) RedactLine(line string, options ...GetArgsOption) string {
+	return r.Join(GetArgs(line, options...))
+}
@@ -0,0 +1,42 @@
+package args
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRedactor(test *testing.T) {
+	r := NewRedactor()
+
+	args := []string{"login", "--user=joe", "--password=s3cr3t", "--github-token", "abc", "-v", "--", "--password=literal"}
+	expected := []string{"login", "--user=joe", "--password=REDACTED", "--github-token", "REDACTED", "-v", "--", "--password=literal"}
+
+	if redacted := r.Redact(args); !reflect.DeepEqual(redacted, expected) {
+		test.Errorf("expected %q got %q", expected, redacted)
+	}
+
+	if args[2] != "--password=s3cr3t" {
+		test.Errorf("Redact modified the input")
+	}
+
+	if s, expected := r.RedactLine(`login --password -s3cret -v`), `login --password REDACTED -v`; s != expected {
+		test.Errorf("expected %v got %v", expected, s)
+	}
+
+	if s, expected := r.RedactLine(`login --password -- x`), `login --password -- x`; s != expected {
+		test.Errorf("expected %v got %v", expected, s)
+	}
+
+	r = &Redactor{Names: []string{"--pin"}, Mask: "x"}
+	if s, expected := r.RedactLine(`unlock --PIN "12 34" --token=t`), `unlock --PIN x --token=t`; s != expected {
+		test.Errorf("expected %v got %v", expected, s)
+	}
+}
+
+func ExampleRedactor() {
+	r := NewRedactor()
+	fmt.Println(r.RedactLine(`mysql -u root --password="my secret" db`, Concat()))
+	// Output:
+	// mysql -u root --password=REDACTED db
+}
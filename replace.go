@@ -0,0 +1,58 @@
+package args
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ReplaceToken replaces the token at index (see IndexToken) with value, leaving the rest of the line unchanged.
+// The new value is quoted in the same style as the original token, if possible (see Quote).
+func ReplaceToken(line string, index int, value string, options ...GetArgsOption) (string, error) {
+	replaced := line
+	found := false
+	settings := getScanner("", options...) // for the quoting rules
+
+	err := scanTokens(line, options, func(i int, tok Token) bool {
+		if i != index || len(tok.Segments) == 0 {
+			return i < index
+		}
+
+		start := tok.Segments[0].Start
+		end := tok.Segments[len(tok.Segments)-1].End
+
+		replaced = line[:start] + quoteLike(value, tok.Segments[0].Quote, settings) + line[end:]
+		found = true
+		return false
+	})
+
+	if found {
+		return replaced, nil
+	}
+	if err != nil {
+		return line, err
+	}
+
+	return line, wrapDetail(ErrNoSuchToken, strconv.Itoa(index))
+}
+
+// quoteLike quotes the value using the quote character (if possible), otherwise only if needed (see Quote).
+// Single quotes are only used if the value can be read back as is with the scanner settings
+// (no escape character, unless escapes are not processed in single quotes).
+func quoteLike(value string, quote rune, scanner *Scanner) string {
+	switch quote {
+	case '\'':
+		literal := scanner.POSIXQuotes || scanner.NoEscape || !strings.ContainsRune(value, scanner.escapeChar())
+		if literal && !strings.ContainsRune(value, '\'') {
+			return "'" + value + "'"
+		}
+
+	case '"':
+		if q := Quote(value); strings.HasPrefix(q, `"`) {
+			return q
+		}
+
+		return `"` + value + `"`
+	}
+
+	return Quote(value)
+}
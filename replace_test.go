@@ -0,0 +1,43 @@
+package args
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReplaceToken(test *testing.T) {
+	for _, c := range []struct {
+		line     string
+		index    int
+		value    string
+		expected string
+	}{
+		{`cp   'a file'  dest   # keep`, 1, "other file", `cp   'other file'  dest   # keep`},
+		{`cp "a" dest`, 1, `say "hi"`, `cp "say \"hi\"" dest`},
+		{`cp "a" dest`, 1, "b", `cp "b" dest`},
+		{`cp 'a' dest`, 1, "it's", `cp "it's" dest`},
+		{`cp a  dest`, 2, "my dir", `cp a  "my dir"`},
+		{`cp a\ b dest`, 1, "c", `cp c dest`},
+		{`set {"a": 1} x`, 1, `{"a": 2}`, `set "{\"a\": 2}" x`},
+		{`cp 'a' b`, 1, `c:\dir`, `cp "c:\\dir" b`},
+	} {
+		s, err := ReplaceToken(c.line, c.index, c.value)
+		if err != nil {
+			test.Errorf("%v: unexpected error %v", c.line, err)
+		} else if s != c.expected {
+			test.Errorf("%v: expected %v got %v", c.line, c.expected, s)
+		}
+	}
+
+	if s, err := ReplaceToken(`cp 'a' b`, 1, `c:\dir`); err != nil || GetArgs(s)[1] != `c:\dir` {
+		test.Errorf("expected c:\\dir to round-trip, got %v", s)
+	}
+
+	if s, _ := ReplaceToken(`cp 'a' b`, 1, `c:\dir`, POSIXQuotes()); s != `cp 'c:\dir' b` {
+		test.Errorf("expected single quotes with POSIXQuotes, got %v", s)
+	}
+
+	if _, err := ReplaceToken(`a b`, 2, "c"); !errors.Is(err, ErrNoSuchToken) {
+		test.Errorf("expected ErrNoSuchToken, got %v", err)
+	}
+}
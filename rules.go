@@ -0,0 +1,78 @@
+package args
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// Rule is a lexer rule, tried at the beginning of each unquoted word (before brackets, operators and symbols).
+//
+// Match is called with the word (the input up to the next space, with no quote or escape processing)
+// and returns the length in bytes of the matching prefix (0 if the rule doesn't match) and the token type.
+// When multiple rules match, the longest match wins (the first rule, for matches of the same length).
+type Rule interface {
+	Match(word string) (n int, typ TokenType)
+}
+
+// RuleFunc is an adapter to use a function as a Rule
+type RuleFunc func(word string) (int, TokenType)
+
+// Match calls f(word)
+func (f RuleFunc) Match(word string) (int, TokenType) {
+	return f(word)
+}
+
+// Rules adds lexer rules to the scanner (see Rule)
+func Rules(rules ...Rule) GetArgsOption {
+	return func(s *Scanner) {
+		s.Rules = append(s.Rules, rules...)
+	}
+}
+
+// matchRule reads the word starting with the last rune read and tries the lexer rules.
+// If a rule matches, it returns the matching text, and the rest of the word is pushed back.
+func (scanner *Scanner) matchRule() (value string, typ TokenType, ok bool, err error) {
+	runes := []scannedRune{scanner.last}
+	word := string(scanner.last.c)
+
+	for {
+		c, _, e := scanner.readRune()
+		if e != nil {
+			if e != io.EOF {
+				err = e
+			}
+			break
+		}
+
+		runes = append(runes, scanner.last)
+
+		if scanner.isSpace(c) {
+			break
+		}
+
+		word += string(c)
+	}
+
+	best := 0
+	for _, rule := range scanner.Rules {
+		if n, t := rule.Match(word); n > best && n <= len(word) {
+			best, typ = n, t
+		}
+	}
+
+	if best == 0 || err != nil {
+		scanner.pushBack(runes[1:]...)
+		return "", typ, false, err
+	}
+
+	//
+	// push back the runes after the match
+	//
+	n, i := 0, 0
+	for ; i < len(runes) && n < best; i++ {
+		n += utf8.RuneLen(runes[i].c)
+	}
+
+	scanner.pushBack(runes[i:]...)
+	return word[:n], typ, true, nil
+}
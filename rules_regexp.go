@@ -0,0 +1,41 @@
+//go:build !tinygo
+
+package args
+
+import "regexp"
+
+// regexpRule is a Rule matching a regular expression
+type regexpRule struct {
+	re  *regexp.Regexp
+	typ TokenType
+}
+
+func (r regexpRule) Match(word string) (int, TokenType) {
+	if loc := r.re.FindStringIndex(word); loc != nil {
+		return loc[1], r.typ
+	}
+
+	return 0, r.typ
+}
+
+// RegexpRule returns a Rule that matches the regular expression at the beginning of a word
+// (using leftmost-longest matching) and returns tokens of the given type
+func RegexpRule(pattern string, typ TokenType) (Rule, error) {
+	re, err := regexp.Compile(`^(?:` + pattern + `)`)
+	if err != nil {
+		return nil, err
+	}
+
+	re.Longest()
+	return regexpRule{re: re, typ: typ}, nil
+}
+
+// MustRegexpRule is like RegexpRule but panics if the pattern cannot be parsed
+func MustRegexpRule(pattern string, typ TokenType) Rule {
+	rule, err := RegexpRule(pattern, typ)
+	if err != nil {
+		panic(err)
+	}
+
+	return rule
+}
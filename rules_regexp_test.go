@@ -0,0 +1,44 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegexpRule(test *testing.T) {
+	const (
+		IPv6Token = CustomToken + 10 + iota
+		NumberToken
+		HexToken
+	)
+
+	rules := Rules(
+		MustRegexpRule(`\[[0-9a-fA-F:]+\](:\d+)?`, IPv6Token),
+		MustRegexpRule(`\d+`, NumberToken),
+		MustRegexpRule(`0x[0-9a-f]+|\d+`, HexToken),
+	)
+
+	scanner := NewScannerString(`connect [::1]:8080 [1, 2] 0x1f 42 42x`)
+	rules(scanner)
+
+	var tokens []string
+	for {
+		tok, err := scanner.Next()
+		if err != nil {
+			break
+		}
+
+		tokens = append(tokens, tok.Type.String()+":"+tok.Value)
+	}
+
+	expected := []string{"word:connect", "custom(10):[::1]:8080", "word:[1, 2]", "custom(12):0x1f", "custom(11):42", "custom(11):42", "word:x"}
+	if !reflect.DeepEqual(tokens, expected) {
+		test.Errorf("expected %q got %q", expected, tokens)
+	}
+
+	if _, err := RegexpRule(`[`, WordToken); err == nil {
+		test.Errorf("expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,66 @@
+package args
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	KeyValueToken = CustomToken + iota
+	RangeToken
+)
+
+// keyValue matches key:value
+func keyValue(word string) (int, TokenType) {
+	if i := strings.IndexByte(word, ':'); i > 0 && i < len(word)-1 {
+		return len(word), KeyValueToken
+	}
+
+	return 0, WordToken
+}
+
+// dateRange matches date..date, stopping at the first non-date character
+func dateRange(word string) (int, TokenType) {
+	n := strings.IndexFunc(word, func(c rune) bool { return !strings.ContainsRune("0123456789-.", c) })
+	if n < 0 {
+		n = len(word)
+	}
+
+	if strings.Contains(word[:n], "..") {
+		return n, RangeToken
+	}
+
+	return 0, WordToken
+}
+
+func TestRules(test *testing.T) {
+	scanner := NewScannerString(`find status:open 2020-01-01..2020-02-01|sort "a:b" résumé:ok`)
+	Rules(RuleFunc(keyValue), RuleFunc(dateRange))(scanner)
+	scanner.TrackSegments = true
+
+	var tokens []Token
+	for {
+		tok, err := scanner.Next()
+		if err != nil {
+			break
+		}
+
+		tok.Line = 0
+		tokens = append(tokens, tok)
+	}
+
+	expected := []Token{
+		{Value: "find", Delim: ' ', End: EndSpace, Segments: []Segment{{"find", NO_QUOTE, 0, 4}}},
+		{Value: "status:open", Type: KeyValueToken, End: EndRule, Segments: []Segment{{"status:open", NO_QUOTE, 5, 16}}},
+		{Value: "2020-01-01..2020-02-01", Type: RangeToken, End: EndRule, Segments: []Segment{{"2020-01-01..2020-02-01", NO_QUOTE, 17, 39}}},
+		{Value: "|", Type: SymbolToken, Delim: '|', End: EndSymbol, Segments: []Segment{{"|", NO_QUOTE, 39, 40}}},
+		{Value: "sort", Delim: ' ', End: EndSpace, Segments: []Segment{{"sort", NO_QUOTE, 40, 44}}},
+		{Value: "a:b", Delim: '"', End: EndQuote, Quoted: true, Segments: []Segment{{"a:b", '"', 45, 50}}},
+		{Value: "résumé:ok", Type: KeyValueToken, End: EndRule, Segments: []Segment{{"résumé:ok", NO_QUOTE, 51, 62}}},
+	}
+
+	if !reflect.DeepEqual(tokens, expected) {
+		test.Errorf("expected %v\ngot %v", expected, tokens)
+	}
+}
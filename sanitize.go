@@ -0,0 +1,107 @@
+package args
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SanitizeMode is what Sanitize does with the constructs that are not allowed
+type SanitizeMode int
+
+const (
+	SanitizeEscape SanitizeMode = iota // escape them, so that they are passed as literal text
+	SanitizeStrip                      // remove them
+	SanitizeReject                     // return an error (see ErrUnsafeInput)
+)
+
+// Policy configures Sanitize
+type Policy struct {
+	Allow []string // operators allowed as is (i.e. "|", "&&", ">"), matching the whole operator ("|" doesn't allow "||")
+	Mode  SanitizeMode
+}
+
+// Sanitize escapes, strips or rejects (according to the policy) the constructs that a shell would interpret
+// (see Risks: metacharacters, redirections, substitutions, expansions, globs, a leading ~, braces and comments),
+// except for the allowed operators, so that the result can be passed to "sh -c".
+// Malformed lines (unterminated quotes, trailing escapes) are always rejected.
+func Sanitize(line string, policy Policy) (string, error) {
+	allow := map[string]bool{}
+	for _, op := range policy.Allow {
+		allow[op] = true
+	}
+
+	var b strings.Builder
+	last := 0    // end of the text copied so far
+	skipTo := -1 // end of the last allowed operator
+	opEnd := -1  // end of the last disallowed operator
+
+	for _, risk := range Risks(line) {
+		if risk.Offset < skipTo {
+			continue
+		}
+
+		if risk.Kind == RiskMalformed {
+			return "", &ParseError{Offset: risk.Offset, Err: ErrUnsafeInput, Detail: risk.Kind.String()}
+		}
+
+		if !risk.Quoted && risk.Kind != RiskExpansion && risk.Offset >= opEnd {
+			op := operatorAt(line[risk.Offset:], risk.Text)
+			if allow[op] {
+				skipTo = risk.Offset + len(op)
+				continue
+			}
+
+			opEnd = risk.Offset + len(op) // the rest of a disallowed operator is not allowed either (| in ||)
+		}
+
+		switch policy.Mode {
+		case SanitizeReject:
+			return "", &ParseError{Offset: risk.Offset, Err: ErrUnsafeInput, Detail: risk.Kind.String() + " " + strconv.Quote(risk.Text)}
+
+		case SanitizeStrip:
+			b.WriteString(line[last:risk.Offset])
+
+		default:
+			b.WriteString(line[last:risk.Offset])
+
+			switch {
+			case risk.Text == "\n":
+				b.WriteString("'\n'") // a backslash-newline would be removed
+
+			case risk.Quoted || risk.Kind == RiskExpansion && isNameChar(risk.Text[len(risk.Text)-1]):
+				// in double quotes only $ and ` are special, $NAME is literal if the $ is escaped
+				b.WriteByte('\\')
+				b.WriteString(risk.Text)
+
+			default:
+				for _, c := range risk.Text {
+					b.WriteByte('\\')
+					b.WriteRune(c)
+				}
+			}
+		}
+
+		last = risk.Offset + len(risk.Text)
+	}
+
+	b.WriteString(line[last:])
+	return b.String(), nil
+}
+
+// shellOperators are the multi-character operators that Sanitize compares with Policy.Allow
+// (i.e. "|" doesn't allow "||")
+var shellOperators = []string{"<<-", ">>", "<<", "&&", "||", ";;", "|&", ">&", "<&", "<>", ">|", "&>"}
+
+// operatorAt returns the operator at the beginning of s: the longest of the shell operators
+// and the text of the risk found there
+func operatorAt(s, text string) string {
+	op := text
+
+	for _, o := range shellOperators {
+		if len(o) > len(op) && strings.HasPrefix(s, o) {
+			op = o
+		}
+	}
+
+	return op
+}
@@ -0,0 +1,58 @@
+package args
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSanitize(test *testing.T) {
+	tests := []struct {
+		line     string
+		policy   Policy
+		expected string
+	}{
+		{`ls -l "my dir"`, Policy{}, `ls -l "my dir"`},
+		{`ls; rm -rf /`, Policy{}, `ls\; rm -rf /`},
+		{`echo $(id) "$(id)" $HOME`, Policy{}, `echo \$\(id\) "\$(id)" \$HOME`},
+		{"echo `id`", Policy{}, "echo \\`id\\`"},
+		{"a\nb", Policy{}, "a'\n'b"},
+		{`cat a | grep b && c & d > e`, Policy{Allow: []string{"|", "&&"}}, `cat a | grep b && c \& d \> e`},
+		{`ls; rm -rf / > out`, Policy{Mode: SanitizeStrip}, `ls rm -rf /  out`},
+		{`sort <(ls)`, Policy{Mode: SanitizeStrip}, `sort ls`},
+		{`rm -rf * a?`, Policy{}, `rm -rf \* a\?`},
+		{`cat ~root/.ssh/id_rsa`, Policy{}, `cat \~root/.ssh/id_rsa`},
+		{`echo {a,b} [ab]`, Policy{}, `echo \{a,b} \[ab]`},
+		{`rm -rf / #x`, Policy{}, `rm -rf / \#x`},
+		{`rm -rf * ~root {a,b} #x`, Policy{Mode: SanitizeStrip}, `rm -rf  root a,b} x`},
+		{`echo $* $?x $# ${a,b} "$*" $1`, Policy{}, `echo \$\* \$\?x \$\# \$\{a,b} "\$*" \$1`},
+		{`cat <(id) a || b | c`, Policy{Allow: []string{"<", "|"}}, `cat \<\(id\) a \|\| b | c`},
+		{`cat <(id) a 2>&1 >> x`, Policy{Allow: []string{"<(", ">&", ">>"}}, `cat <(id\) a 2>&1 >> x`},
+	}
+
+	for _, t := range tests {
+		sanitized, err := Sanitize(t.line, t.policy)
+		if err != nil {
+			test.Errorf("%q: unexpected error %v", t.line, err)
+		} else if sanitized != t.expected {
+			test.Errorf("%q: expected %q got %q", t.line, t.expected, sanitized)
+		}
+
+		if t.policy.Mode == SanitizeEscape && len(t.policy.Allow) == 0 && !IsSafe(sanitized) {
+			test.Errorf("%q: sanitized line is not safe: %v", sanitized, Risks(sanitized))
+		}
+	}
+
+	for _, line := range []string{`ls; rm`, `rm -rf *`, `cat ~root/x`, `echo {a,b}`, `rm -rf / #x`} {
+		if _, err := Sanitize(line, Policy{Mode: SanitizeReject}); !errors.Is(err, ErrUnsafeInput) {
+			test.Errorf("%q: expected ErrUnsafeInput, got %v", line, err)
+		}
+	}
+
+	if _, err := Sanitize(`ls; rm`, Policy{Mode: SanitizeReject}); err == nil || err.Error() != `unsafe input at offset 2: metacharacter ";"` {
+		test.Errorf("unexpected error %v", err)
+	}
+
+	if _, err := Sanitize(`echo "open`, Policy{}); !errors.Is(err, ErrUnsafeInput) {
+		test.Errorf("expected ErrUnsafeInput, got %v", err)
+	}
+}
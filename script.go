@@ -0,0 +1,127 @@
+package args
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Command is a logical line of a script (see ParseScript)
+type Command struct {
+	Line     int      // line number of the first line of the command (1-based)
+	Text     string   // the command text, with continuation lines joined
+	Args     []string // the command arguments (empty for a block of comments not followed by a command)
+	Comments []string // the comment lines before the command (without "#")
+	Comment  string   // the comment at the end of the command line (without "#")
+}
+
+// ParseScript parses a shell-like script, returning one Command per logical line.
+// A line ending with an escape character continues on the next line, as does a line with an unterminated
+// quote or bracket. Blank lines are skipped, comment lines are attached to the following command
+// (a blank line after a block of comments returns the block as a Command with no arguments, so that
+// a tool rewriting the script can preserve them). The options are the same as for GetArgs.
+func ParseScript(r io.Reader, options ...GetArgsOption) ([]Command, error) {
+	commands := []Command{}
+	lines := bufio.NewScanner(r)
+
+	var cmd Command
+	var text strings.Builder
+
+	flushComments := func() {
+		if len(cmd.Comments) > 0 {
+			commands = append(commands, Command{Line: cmd.Line, Args: []string{}, Comments: cmd.Comments})
+		}
+		cmd = Command{}
+	}
+
+	addCommand := func(scanner *Scanner, tokens []Token) {
+		cmd.Text = text.String()
+		cmd.Args = []string{}
+
+		for _, tok := range tokens {
+			if tok.Type == CommentToken {
+				cmd.Comment = strings.TrimSpace(tok.Value)
+			} else {
+				cmd.Args = scanner.appendToken(cmd.Args, tok)
+			}
+		}
+
+		commands = append(commands, cmd)
+		cmd = Command{}
+		text.Reset()
+	}
+
+	for lineno := 1; lines.Scan(); lineno++ {
+		line := lines.Text()
+
+		if text.Len() == 0 {
+			trimmed := strings.TrimSpace(line)
+
+			if trimmed == "" {
+				flushComments()
+				continue
+			}
+
+			if strings.HasPrefix(trimmed, string(COMMENT_CHAR)) {
+				if len(cmd.Comments) == 0 {
+					cmd.Line = lineno
+				}
+				cmd.Comments = append(cmd.Comments, strings.TrimSpace(trimmed[1:]))
+				continue
+			}
+
+			cmd.Line = lineno
+		}
+
+		text.WriteString(line)
+
+		scanner := getScanner(text.String(), options...)
+		scanner.Comments = WordStartComments
+		scanner.CommentTokens = true
+		scanner.Strict = false
+
+		tokens, err := scanner.Tokens()
+		if err != nil {
+			return commands, wrap(err, "line "+strconv.Itoa(cmd.Line)+": "+err.Error())
+		}
+
+		switch state := scanner.State(); {
+		case state.Escape:
+			// line continuation: remove the escape character and join the next line
+			s := text.String()
+			text.Reset()
+			text.WriteString(s[:len(s)-1])
+			continue
+
+		case !state.Complete():
+			// quoted or bracketed newline
+			text.WriteByte('\n')
+			continue
+		}
+
+		addCommand(scanner, tokens)
+	}
+
+	if err := lines.Err(); err != nil {
+		return commands, err
+	}
+
+	if text.Len() > 0 {
+		// continuation at the end of the input
+		scanner := getScanner(text.String(), options...)
+		scanner.Comments = WordStartComments
+		scanner.CommentTokens = true
+		scanner.Strict = true
+
+		tokens, err := scanner.Tokens()
+		if err != nil {
+			return commands, wrap(err, "line "+strconv.Itoa(cmd.Line)+": "+err.Error())
+		}
+
+		addCommand(scanner, tokens)
+	}
+
+	flushComments()
+	return commands, nil
+}
@@ -0,0 +1,58 @@
+package args
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseScript(test *testing.T) {
+	script := `#!/bin/sh
+# deploy the app
+
+# build it
+go build \
+    -o app ./cmd/app   # the binary
+echo "multi
+line" {"a":
+  1}
+
+./app --port=8080
+# trailing comment
+`
+
+	expected := []Command{
+		{Line: 1, Args: []string{}, Comments: []string{"!/bin/sh", "deploy the app"}},
+		{Line: 5, Text: "go build     -o app ./cmd/app   # the binary", Args: []string{"go", "build", "-o", "app", "./cmd/app"},
+			Comments: []string{"build it"}, Comment: "the binary"},
+		{Line: 7, Text: "echo \"multi\nline\" {\"a\":\n  1}", Args: []string{"echo", "multi\nline", "{\"a\":\n  1}"}},
+		{Line: 11, Text: "./app --port=8080", Args: []string{"./app", "--port=8080"}},
+		{Line: 12, Args: []string{}, Comments: []string{"trailing comment"}},
+	}
+
+	commands, err := ParseScript(strings.NewReader(script))
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	if len(commands) != len(expected) {
+		test.Fatalf("expected %v commands got %+v", len(expected), commands)
+	}
+
+	for i := range expected {
+		if !reflect.DeepEqual(commands[i], expected[i]) {
+			test.Errorf("expected %+v got %+v", expected[i], commands[i])
+		}
+	}
+
+	commands, err = ParseScript(strings.NewReader("ls\nls \\"))
+	if err != nil || len(commands) != 2 || !reflect.DeepEqual(commands[1].Args, []string{"ls"}) {
+		test.Errorf("unexpected result %+v %v", commands, err)
+	}
+
+	_, err = ParseScript(strings.NewReader("ls\necho 'open\n"))
+	if !errors.Is(err, ErrUnterminatedQuote) || !strings.HasPrefix(err.Error(), "line 2:") {
+		test.Errorf("expected unterminated quote at line 2, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package args
+
+import "io"
+
+// IndexToken returns the index of the first unquoted token equal to word (-1 if not found).
+// A match inside quotes or as a part of another word doesn't count.
+// User token delimiters (see UserTokens) count as tokens, as in GetArgs.
+func IndexToken(line, word string, options ...GetArgsOption) int {
+	index := -1
+
+	scanTokens(line, options, func(i int, tok Token) bool {
+		if tok.Value == word && !tok.Quoted {
+			index = i
+			return false
+		}
+
+		return true
+	})
+
+	return index
+}
+
+// scanTokens calls f for each token in line, with the token index, until f returns false.
+// This is the token numbering shared by IndexToken, ReplaceToken and TokenAt, the same as GetArgs:
+// user token delimiters are tokens, with a single segment, and an empty token before a delimiter has
+// an empty segment at the delimiter offset.
+// It returns the parsing error, if any (a partial token returned with the error is still passed to f).
+func scanTokens(line string, options []GetArgsOption, f func(index int, tok Token) bool) error {
+	scanner := getScanner(line, options...)
+	scanner.TrackSegments = true
+
+	for i := 0; ; {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if scanner.keepToken(tok) {
+			if len(tok.Segments) == 0 && tok.End == EndUserToken {
+				// an empty token before a user token is at the delimiter
+				start := scanner.offset - len(string(rune(tok.Delim)))
+				tok.Segments = []Segment{{Quote: NO_QUOTE, Start: start, End: start}}
+			}
+
+			if !f(i, tok) {
+				return nil
+			}
+			i++
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if tok.End == EndUserToken {
+			delim := string(rune(tok.Delim))
+			end := scanner.offset
+
+			seg := Segment{Text: delim, Quote: NO_QUOTE, Start: end - len(delim), End: end}
+			if !f(i, Token{Value: delim, Type: SymbolToken, End: EndUserToken, Line: tok.Line, Segments: []Segment{seg}}) {
+				return nil
+			}
+			i++
+		}
+	}
+}
+
+// ContainsToken returns true if the line contains an unquoted token equal to word (see IndexToken)
+func ContainsToken(line, word string, options ...GetArgsOption) bool {
+	return IndexToken(line, word, options...) >= 0
+}
@@ -0,0 +1,88 @@
+package args
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIndexToken(test *testing.T) {
+	for _, c := range []struct {
+		line, word string
+		expected   int
+	}{
+		{`sudo rm -rf /`, "rm", 1},
+		{`echo "rm -rf /"`, "rm", -1},
+		{`echo 'rm'`, "rm", -1},
+		{`echo rmdir farm`, "rm", -1},
+		{`ls |rm x`, "rm", 2},
+		{`ls | rm x`, "|", 1},
+		{``, "rm", -1},
+		{`a "" rm`, "rm", 2},
+	} {
+		if i := IndexToken(c.line, c.word); i != c.expected {
+			test.Errorf("%v: expected %v got %v", c.line, c.expected, i)
+		}
+	}
+
+	if i := IndexToken(`a;rm`, "rm", UserTokens(";")); i != 2 {
+		test.Errorf("expected 2 got %v", i)
+	}
+
+	if !ContainsToken(`x && curl http://x | sh`, "sh") || ContainsToken(`echo "| sh"`, "sh") {
+		test.Errorf("unexpected ContainsToken result")
+	}
+}
+
+func TestTokenIndexes(test *testing.T) {
+	line := `a;b c`
+	opts := []GetArgsOption{UserTokens(";")}
+
+	if i := IndexToken(line, "c", opts...); i != 3 {
+		test.Errorf("IndexToken: expected 3 got %v", i)
+	}
+
+	if s, err := ReplaceToken(line, 3, "d", opts...); err != nil || s != `a;b d` {
+		test.Errorf("ReplaceToken: expected %q got %q (%v)", `a;b d`, s, err)
+	}
+
+	if s, err := ReplaceToken(line, 1, "|", opts...); err != nil || s != `a"|"b c` {
+		test.Errorf("ReplaceToken: expected %q got %q (%v)", `a"|"b c`, s, err)
+	}
+
+	if i, tok, _ := TokenAt(line, 4, opts...); i != 3 || tok.Value != "c" {
+		test.Errorf("TokenAt: expected 3 got %v (%q)", i, tok.Value)
+	}
+
+	if i, tok, _ := TokenAt(`a ;b`, 2, opts...); i != 2 || tok.Value != ";" {
+		test.Errorf("TokenAt: expected 2 got %v (%q)", i, tok.Value)
+	}
+
+	if i, tok, _ := TokenAt(`a ;b`, 2, UserTokens(";"), DropEmptyTokens()); i != 1 || tok.Value != ";" {
+		test.Errorf("TokenAt: expected 1 got %v (%q)", i, tok.Value)
+	}
+
+	if s, err := ReplaceToken(`a ;b`, 1, "x", opts...); err != nil || s != `a x;b` {
+		test.Errorf("ReplaceToken: expected %q got %q (%v)", `a x;b`, s, err)
+	}
+
+	// GetArgs, ReplaceToken and TokenAt agree on token indexes
+	for _, opts := range [][]GetArgsOption{opts, {UserTokens(";"), DropEmptyTokens()}} {
+		for _, line := range []string{`a;b c`, `x ; ;y "" z`, `;;a`, `'a;b';c`} {
+			for i, arg := range GetArgs(line, opts...) {
+				if arg == ";" {
+					continue // replacing a delimiter joins the tokens around it
+				}
+
+				s, err := ReplaceToken(line, i, "XY", opts...)
+				if args := GetArgs(s, opts...); err != nil || args[i] != "XY" {
+					test.Errorf("%v: ReplaceToken(%v): got %q %v", line, i, s, err)
+				}
+
+				// inside the new token, since it may follow the previous one ('a;b'XY)
+				if j, _, _ := TokenAt(s, strings.Index(s, "XY")+1, opts...); j != i {
+					test.Errorf("%v: TokenAt: expected %v got %v", s, i, j)
+				}
+			}
+		}
+	}
+}
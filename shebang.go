@@ -0,0 +1,44 @@
+package args
+
+import "strings"
+
+// ParseShebang parses the first line of a script ("#!interpreter [arg]") as the operating system does.
+// On Linux (and other systems but macOS and the BSDs) everything after the interpreter, with leading
+// and trailing spaces and tabs removed, is passed as a single argument, with no quote processing.
+// On macOS and the BSDs the arguments are split on spaces and tabs.
+func ParseShebang(line string) (interpreter string, args []string, err error) {
+	return parseShebang(line, splitShebang)
+}
+
+// parseShebang parses a shebang line, splitting the arguments on spaces and tabs if split is true
+func parseShebang(line string, split bool) (interpreter string, args []string, err error) {
+	if !strings.HasPrefix(line, "#!") {
+		return "", nil, &ParseError{Offset: 0, Err: ErrInvalidShebang, Detail: "missing #!"}
+	}
+
+	if n := strings.IndexByte(line, '\n'); n >= 0 {
+		line = line[:n]
+	}
+
+	isBlank := func(c rune) bool { return c == ' ' || c == '\t' }
+
+	rest := strings.TrimFunc(line[2:], isBlank)
+	if rest == "" {
+		return "", nil, &ParseError{Offset: 2, Err: ErrInvalidShebang, Detail: "missing interpreter"}
+	}
+
+	args = []string{}
+
+	n := strings.IndexFunc(rest, isBlank)
+	if n < 0 {
+		return rest, args, nil
+	}
+
+	interpreter, rest = rest[:n], strings.TrimLeftFunc(rest[n:], isBlank)
+
+	if split {
+		return interpreter, strings.FieldsFunc(rest, isBlank), nil
+	}
+
+	return interpreter, append(args, rest), nil
+}
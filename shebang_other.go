@@ -0,0 +1,6 @@
+//go:build !(darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package args
+
+// splitShebang is true if the shebang arguments are split on spaces and tabs (see ParseShebang)
+const splitShebang = false
@@ -0,0 +1,43 @@
+package args
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseShebang(test *testing.T) {
+	tests := []struct {
+		line        string
+		split       bool
+		interpreter string
+		args        []string
+	}{
+		{"#!/bin/sh", false, "/bin/sh", []string{}},
+		{"#! /bin/sh\n", false, "/bin/sh", []string{}},
+		{"#!/usr/bin/env python3 \n", false, "/usr/bin/env", []string{"python3"}},
+		{"#!/usr/bin/env -S deno run  --allow-net", false, "/usr/bin/env", []string{"-S deno run  --allow-net"}},
+		{"#!/usr/bin/env -S deno run  --allow-net", true, "/usr/bin/env", []string{"-S", "deno", "run", "--allow-net"}},
+		{"#!/bin/awk\t-f \"x y\"", false, "/bin/awk", []string{`-f "x y"`}},
+		{"#!/bin/sh\r", false, "/bin/sh\r", []string{}},
+	}
+
+	for _, t := range tests {
+		interpreter, args, err := parseShebang(t.line, t.split)
+		if err != nil {
+			test.Errorf("%q: unexpected error %v", t.line, err)
+		} else if interpreter != t.interpreter || !reflect.DeepEqual(args, t.args) {
+			test.Errorf("%q: expected %q %q got %q %q", t.line, t.interpreter, t.args, interpreter, args)
+		}
+	}
+
+	for _, line := range []string{"/bin/sh", "#!", "#!  \t"} {
+		if _, _, err := ParseShebang(line); !errors.Is(err, ErrInvalidShebang) {
+			test.Errorf("%q: expected ErrInvalidShebang, got %v", line, err)
+		}
+	}
+
+	if interpreter, _, err := ParseShebang("#!/bin/bash -e"); err != nil || interpreter != "/bin/bash" {
+		test.Errorf("unexpected result %q %v", interpreter, err)
+	}
+}
@@ -0,0 +1,64 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// Attrs returns the options (as an "options" group, sorted by name) and the arguments as slog attributes.
+// If r is not nil, the values of sensitive options are masked, including a value in the following argument
+// (--password secret, where secret is parsed as the first argument).
+func (a Args) Attrs(r *Redactor) []slog.Attr {
+	names := make([]string, 0, len(a.Options))
+	for name := range a.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	options := make([]slog.Attr, len(names))
+	for i, name := range names {
+		value := a.Options[name]
+		if r != nil && r.IsSensitive(name) {
+			value = r.mask()
+		}
+
+		options[i] = slog.String(name, value)
+	}
+
+	arguments := a.Arguments
+	if r != nil {
+		arguments = r.maskArguments(a)
+	}
+
+	return []slog.Attr{
+		{Key: "options", Value: slog.GroupValue(options...)},
+		slog.Any("arguments", arguments),
+	}
+}
+
+// maskArguments returns the arguments, with the first one masked if it follows a sensitive option
+// with no value (see Redactor.Redact)
+func (r *Redactor) maskArguments(a Args) []string {
+	last, index := "", -1
+	for name, p := range a.Positions {
+		if p.Index > index {
+			last, index = name, p.Index
+		}
+	}
+
+	if index < 0 || len(a.Arguments) == 0 || a.Verbatim != "" || a.HasValue[last] || a.Options[last] != "" || !r.IsSensitive(last) {
+		return a.Arguments
+	}
+
+	masked := append([]string{}, a.Arguments...)
+	masked[0] = r.mask()
+	return masked
+}
+
+// LogValue implements slog.LogValuer, so that Args can be logged as structured attributes.
+// The values of sensitive options (see SENSITIVE_OPTIONS) are masked.
+func (a Args) LogValue() slog.Value {
+	return slog.GroupValue(a.Attrs(NewRedactor())...)
+}
@@ -0,0 +1,48 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogValue(test *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	logger.Info("command", "cmd", ParseArgs("--user=joe --token=abc -v deploy web"))
+
+	expected := "level=INFO msg=command cmd.options.token=REDACTED cmd.options.user=joe cmd.options.v=\"\" cmd.arguments=\"[deploy web]\"\n"
+	if s := buf.String(); s != expected {
+		test.Errorf("expected %q got %q", expected, s)
+	}
+
+	buf.Reset()
+	logger.Info("command", "cmd", ParseArgs("--password hunter2 --user bob"))
+
+	expected = "level=INFO msg=command cmd.options.password=REDACTED cmd.arguments=\"[REDACTED --user bob]\"\n"
+	if s := buf.String(); s != expected {
+		test.Errorf("expected %q got %q", expected, s)
+	}
+
+	for _, line := range []string{"-v --password -- x", "--password= x", "--password -v x"} {
+		if attrs := ParseArgs(line).Attrs(NewRedactor()); !strings.Contains(attrs[1].String(), "x") {
+			test.Errorf("%v: unexpected masked argument %v", line, attrs[1])
+		}
+	}
+
+	if attrs := ParseArgs("--token=abc").Attrs(nil); !strings.Contains(attrs[0].String(), "token=abc") {
+		test.Errorf("expected unredacted value, got %v", attrs)
+	}
+}
@@ -0,0 +1,487 @@
+package args
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// OptionType is the type of the value of a declared option
+type OptionType int
+
+const (
+	StringOption OptionType = iota
+	IntOption
+	BoolOption
+)
+
+func (t OptionType) String() string {
+	switch t {
+	case StringOption:
+		return "string"
+	case IntOption:
+		return "int"
+	case BoolOption:
+		return "bool"
+	}
+
+	return "OptionType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// OptionSpec declares an option accepted by a command
+type OptionSpec struct {
+	Name        string // option name, without dashes
+	Type        OptionType
+	Default     string // default value, as a string
+	Description string
+	Required    bool
+	Hidden      bool   // not listed in the help text
+	Deprecated  bool   // accepted, with a warning (see Args.Warnings)
+	ReplacedBy  string // for deprecated options, the name of the replacement option (its value is set, if missing)
+	Group       string // help section for the option (i.e. "Output options"), default "Options"
+
+	Choices  []string                     // values offered for completion (see Spec.Complete)
+	Complete func(prefix string) []string // returns the values that complete prefix (overrides Choices)
+}
+
+// ArgSpec declares a positional argument accepted by a command
+type ArgSpec struct {
+	Name        string
+	Description string
+	Optional    bool
+}
+
+// Spec declares the options and arguments accepted by a command, to be used with ParseArgs
+type Spec struct {
+	Name        string // command name
+	Description string
+	Options     []OptionSpec
+	Arguments   []ArgSpec
+	Variadic    bool           // the last argument can be repeated
+	Formatter   *HelpFormatter // custom help formatting (optional)
+	Exclusive   [][]string     // groups of mutually exclusive options (at most one option per group can be set)
+
+	// Prompt, if set, is called to obtain the value of a missing required option or argument
+	// (i.e. to ask the user in an interactive session)
+	Prompt func(name, description string) (string, error)
+
+	// Abbreviations enables GNU-style abbreviations of long options (i.e. --num for --number), if unambiguous
+	Abbreviations bool
+}
+
+// attachedValues splits short options with an attached value (i.e. -n42 or -ofile.txt)
+// for declared single letter options that take a value
+func (s *Spec) attachedValues(parsed *Args) {
+	declared := map[string]bool{}
+	for _, o := range s.Options {
+		declared[o.Name] = true
+	}
+
+	for _, o := range s.Options {
+		if len(o.Name) != 1 || o.Type == BoolOption {
+			continue
+		}
+
+		for arg := range parsed.short {
+			if len(arg) < 2 || !strings.HasPrefix(arg, o.Name) || declared[arg] {
+				continue
+			}
+
+			if _, ok := parsed.Options[o.Name]; !ok {
+				parsed.Options[o.Name] = arg[len(o.Name):]
+				parsed.HasValue[o.Name] = true
+				parsed.Positions[o.Name] = parsed.Positions[arg]
+			}
+
+			delete(parsed.Options, arg)
+			delete(parsed.Positions, arg)
+			delete(parsed.short, arg)
+		}
+	}
+}
+
+// expandAbbreviations replaces abbreviated long options (with a double dash) with the declared option they are a prefix of
+func (s *Spec) expandAbbreviations(parsed *Args) error {
+	declared := map[string]bool{}
+	for _, o := range s.Options {
+		declared[o.Name] = true
+	}
+
+	names := make([]string, 0, len(parsed.Options))
+	for name := range parsed.Options {
+		if !declared[name] && parsed.long[name] { // -nu is not --number
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		candidates := []string{}
+
+		for _, o := range s.Options {
+			if len(o.Name) > 1 && strings.HasPrefix(o.Name, name) {
+				candidates = append(candidates, o.Name)
+			}
+		}
+
+		switch len(candidates) {
+		case 0:
+			continue
+
+		case 1:
+			full := candidates[0]
+			if _, ok := parsed.Options[full]; !ok {
+				parsed.Options[full] = parsed.Options[name]
+				parsed.HasValue[full] = parsed.HasValue[name]
+				parsed.Positions[full] = parsed.Positions[name]
+			}
+
+			delete(parsed.Options, name)
+			delete(parsed.HasValue, name)
+			delete(parsed.Positions, name)
+
+		default:
+			for i, c := range candidates {
+				candidates[i] = optionFlag(c)
+			}
+
+			return wrapDetail(ErrAmbiguousOption, optionFlag(name)+" could be "+strings.Join(candidates, ", "))
+		}
+	}
+
+	return nil
+}
+
+// Parse parses the input line (see ParseArgs) and verifies that all required options and arguments are present.
+// Declared single letter options that take a value accept the value attached to the option (i.e. -n42, -ofile.txt).
+// Missing values are requested via Prompt, if set, otherwise an error is returned (see ErrMissingOption, ErrMissingArgument)
+func (s *Spec) Parse(line string, options ...GetArgsOption) (Args, error) {
+	parsed := ParseArgs(line, options...)
+	s.attachedValues(&parsed)
+
+	if s.Abbreviations {
+		if err := s.expandAbbreviations(&parsed); err != nil {
+			return parsed, err
+		}
+	}
+
+	for _, o := range s.Options {
+		if v, ok := parsed.Options[o.Name]; ok && o.Deprecated {
+			warning := "option " + optionFlag(o.Name) + " is deprecated"
+
+			if o.ReplacedBy != "" {
+				warning += ", use " + optionFlag(o.ReplacedBy) + " instead"
+
+				if _, ok := parsed.Options[o.ReplacedBy]; !ok {
+					parsed.Options[o.ReplacedBy] = v
+					parsed.HasValue[o.ReplacedBy] = parsed.HasValue[o.Name]
+					parsed.Positions[o.ReplacedBy] = parsed.Positions[o.Name]
+				}
+			}
+
+			parsed.Warnings = append(parsed.Warnings, warning)
+		}
+	}
+
+	for _, group := range s.Exclusive {
+		set := []string{}
+
+		for _, name := range group {
+			if _, ok := parsed.Options[name]; ok {
+				set = append(set, optionFlag(name))
+			}
+		}
+
+		if len(set) > 1 {
+			return parsed, wrapDetail(ErrConflictingOptions, strings.Join(set, ", "))
+		}
+	}
+
+	for _, o := range s.Options {
+		if _, ok := parsed.Options[o.Name]; ok || !o.Required {
+			continue
+		}
+
+		if s.Prompt == nil {
+			return parsed, wrapDetail(ErrMissingOption, o.Synopsis())
+		}
+
+		v, err := s.Prompt(o.Name, o.Description)
+		if err != nil {
+			return parsed, err
+		}
+
+		parsed.Options[o.Name] = v
+		parsed.HasValue[o.Name] = true
+	}
+
+	for i, a := range s.Arguments {
+		if i < len(parsed.Arguments) || a.Optional {
+			continue
+		}
+
+		if s.Prompt == nil {
+			return parsed, wrapDetail(ErrMissingArgument, a.Name)
+		}
+
+		v, err := s.Prompt(a.Name, a.Description)
+		if err != nil {
+			return parsed, err
+		}
+
+		parsed.Arguments = append(parsed.Arguments, v)
+	}
+
+	return parsed, nil
+}
+
+// Usage returns a one line summary of the command syntax (i.e. "Usage: cp [options] <source> [<dest>]")
+func (s *Spec) Usage() string {
+	var b strings.Builder
+
+	b.WriteString("Usage: ")
+	b.WriteString(s.Name)
+
+	for _, o := range s.Options {
+		if !o.Hidden {
+			b.WriteString(" [options]")
+			break
+		}
+	}
+
+	for i, a := range s.Arguments {
+		name := "<" + a.Name + ">"
+		if s.Variadic && i == len(s.Arguments)-1 {
+			name += "..."
+		}
+		if a.Optional {
+			name = "[" + name + "]"
+		}
+
+		b.WriteString(" ")
+		b.WriteString(name)
+	}
+
+	return b.String()
+}
+
+// Help returns the full help text for the command: usage, description, arguments and options
+// (formatted according to Formatter, if set)
+func (s *Spec) Help() string {
+	f := s.Formatter
+	if f == nil {
+		f = &HelpFormatter{}
+	}
+
+	return f.Format(s)
+}
+
+// HelpFormatter controls how Spec.Help renders the help text.
+// All fields are optional: the zero value produces the default format.
+type HelpFormatter struct {
+	Indent    int // indentation of argument and option rows (default 2)
+	NameWidth int // width of the name column (0 means fit the longest name)
+	MaxWidth  int // maximum line width, descriptions are wrapped (0 means no wrapping)
+
+	Usage    func(s *Spec) string                     // formats the usage line
+	Section  func(title string) string                // formats a section title (i.e. "Options:")
+	Argument func(a ArgSpec, nameWidth int) string    // formats an argument row
+	Option   func(o OptionSpec, nameWidth int) string // formats an option row
+}
+
+// Format returns the help text for the Spec
+func (f *HelpFormatter) Format(s *Spec) string {
+	var b strings.Builder
+
+	if f.Usage != nil {
+		b.WriteString(f.Usage(s))
+	} else {
+		b.WriteString(s.Usage())
+	}
+	b.WriteString("\n")
+
+	if s.Description != "" {
+		b.WriteString("\n")
+		b.WriteString(s.Description)
+		b.WriteString("\n")
+	}
+
+	if len(s.Arguments) > 0 {
+		width := f.NameWidth
+		if width == 0 {
+			for _, a := range s.Arguments {
+				if n := utf8.RuneCountInString(a.Name); n > width {
+					width = n
+				}
+			}
+		}
+
+		f.section(&b, "Arguments")
+
+		for _, a := range s.Arguments {
+			if f.Argument != nil {
+				b.WriteString(f.Argument(a, width))
+			} else {
+				f.row(&b, a.Name, a.Description, width)
+			}
+		}
+	}
+
+	visible := []OptionSpec{}
+	for _, o := range s.Options {
+		if !o.Hidden {
+			visible = append(visible, o)
+		}
+	}
+
+	if len(visible) > 0 {
+		width := f.NameWidth
+		if width == 0 {
+			for _, o := range visible {
+				if n := utf8.RuneCountInString(o.Synopsis()); n > width {
+					width = n
+				}
+			}
+		}
+
+		//
+		// ungrouped options first, then the groups in order of appearance
+		//
+		groups := []string{""}
+		for _, o := range visible {
+			found := false
+			for _, g := range groups {
+				if g == o.Group {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				groups = append(groups, o.Group)
+			}
+		}
+
+		for _, g := range groups {
+			title := g
+			if title == "" {
+				title = "Options"
+			}
+
+			first := true
+
+			for _, o := range visible {
+				if o.Group != g {
+					continue
+				}
+
+				if first {
+					f.section(&b, title)
+					first = false
+				}
+
+				if f.Option != nil {
+					b.WriteString(f.Option(o, width))
+				} else {
+					f.row(&b, o.Synopsis(), o.Help(), width)
+				}
+			}
+		}
+	}
+
+	return b.String()
+}
+
+func (f *HelpFormatter) section(b *strings.Builder, title string) {
+	b.WriteString("\n")
+
+	if f.Section != nil {
+		b.WriteString(f.Section(title))
+	} else {
+		b.WriteString(title + ":\n")
+	}
+}
+
+// row writes a (name, description) row, wrapping the description if needed
+func (f *HelpFormatter) row(b *strings.Builder, name, desc string, width int) {
+	indent := f.Indent
+	if indent == 0 {
+		indent = 2
+	}
+
+	prefix := strings.Repeat(" ", indent)
+	descCol := indent + width + 2
+
+	if utf8.RuneCountInString(name) > width {
+		// name too long, description goes on the next line
+		b.WriteString(prefix + name + "\n")
+		name = ""
+	}
+
+	lines := []string{desc}
+	if f.MaxWidth > descCol {
+		lines = wrapText(desc, f.MaxWidth-descCol)
+	}
+
+	for i, line := range lines {
+		if i > 0 {
+			name = ""
+		}
+
+		b.WriteString(prefix + name + strings.Repeat(" ", width-utf8.RuneCountInString(name)) + "  " + line + "\n")
+	}
+}
+
+// wrapText splits text in lines of at most width characters (unless a single word is longer)
+func wrapText(text string, width int) []string {
+	lines := []string{}
+	line := ""
+
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+
+	return append(lines, line)
+}
+
+// Synopsis returns the option syntax (i.e. "--number=int", "--verbose" or "-v")
+func (o OptionSpec) Synopsis() string {
+	if o.Type == BoolOption {
+		return optionFlag(o.Name)
+	}
+
+	return optionFlag(o.Name) + "=" + o.Type.String()
+}
+
+// optionFlag returns the option name with the dash prefix (i.e. "-v" or "--verbose")
+func optionFlag(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+
+	return "--" + name
+}
+
+// Help returns the option description, including the default value (if any)
+func (o OptionSpec) Help() string {
+	desc := o.Description
+
+	if o.Default != "" {
+		desc += " (default " + o.Default + ")"
+	}
+
+	if o.Deprecated {
+		desc += " (deprecated)"
+	}
+
+	return desc
+}
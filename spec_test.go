@@ -0,0 +1,281 @@
+package args
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testSpec = Spec{
+	Name:        "copy",
+	Description: "Copy files.",
+	Options: []OptionSpec{
+		{Name: "l", Type: BoolOption, Description: "list something"},
+		{Name: "number", Type: IntOption, Default: "42", Description: "a number option"},
+		{Name: "where", Description: "a string option"},
+	},
+	Arguments: []ArgSpec{
+		{Name: "source", Description: "source files"},
+		{Name: "dest", Description: "destination directory", Optional: true},
+	},
+}
+
+func ExampleSpec_Help() {
+	fmt.Print(testSpec.Help())
+	// Output:
+	// Usage: copy [options] <source> [<dest>]
+	//
+	// Copy files.
+	//
+	// Arguments:
+	//   source  source files
+	//   dest    destination directory
+	//
+	// Options:
+	//   -l              list something
+	//   --number=int    a number option (default 42)
+	//   --where=string  a string option
+}
+
+func ExampleHelpFormatter() {
+	spec := testSpec
+	spec.Formatter = &HelpFormatter{
+		Usage: func(s *Spec) string {
+			return "# " + s.Name
+		},
+		Section: func(title string) string {
+			return "## " + title + "\n\n"
+		},
+		Argument: func(a ArgSpec, _ int) string {
+			return fmt.Sprintf("- `%s`: %s\n", a.Name, a.Description)
+		},
+		Option: func(o OptionSpec, _ int) string {
+			return fmt.Sprintf("- `%s`: %s\n", o.Synopsis(), o.Help())
+		},
+	}
+
+	fmt.Print(spec.Help())
+	// Output:
+	// # copy
+	//
+	// Copy files.
+	//
+	// ## Arguments
+	//
+	// - `source`: source files
+	// - `dest`: destination directory
+	//
+	// ## Options
+	//
+	// - `-l`: list something
+	// - `--number=int`: a number option (default 42)
+	// - `--where=string`: a string option
+}
+
+func ExampleHelpFormatter_wrap() {
+	spec := Spec{
+		Name: "wrap",
+		Options: []OptionSpec{
+			{Name: "v", Type: BoolOption, Description: "verbose output, with lots of details about what is going on"},
+		},
+	}
+	spec.Formatter = &HelpFormatter{Indent: 4, MaxWidth: 40}
+
+	fmt.Print(spec.Help())
+	// Output:
+	// Usage: wrap [options]
+	//
+	// Options:
+	//     -v  verbose output, with lots of
+	//         details about what is going on
+}
+
+func TestHelpFormatterUnicode(test *testing.T) {
+	spec := Spec{
+		Name:      "copy",
+		Arguments: []ArgSpec{{Name: "fiché", Description: "source"}, {Name: "dest", Description: "destination"}},
+	}
+	spec.Formatter = &HelpFormatter{NameWidth: 5}
+
+	// the width is in characters, not bytes
+	expected := "Usage: copy <fiché> <dest>\n\nArguments:\n  fiché  source\n  dest   destination\n"
+	if help := spec.Help(); help != expected {
+		test.Errorf("expected %q got %q", expected, help)
+	}
+}
+
+func TestSpecPrompt(test *testing.T) {
+	spec := Spec{
+		Name: "login",
+		Options: []OptionSpec{
+			{Name: "user", Description: "user name", Required: true},
+			{Name: "verbose", Type: BoolOption},
+		},
+		Arguments: []ArgSpec{{Name: "host", Description: "remote host"}},
+	}
+
+	if _, err := spec.Parse("--verbose"); !errors.Is(err, ErrMissingOption) {
+		test.Errorf("expected ErrMissingOption, got %v", err)
+	}
+
+	if _, err := spec.Parse("--user=me"); !errors.Is(err, ErrMissingArgument) {
+		test.Errorf("expected ErrMissingArgument, got %v", err)
+	}
+
+	asked := []string{}
+	spec.Prompt = func(name, description string) (string, error) {
+		asked = append(asked, name)
+		return "value of " + description, nil
+	}
+
+	parsed, err := spec.Parse("--verbose")
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	if len(asked) != 2 || parsed.GetOption("user", "") != "value of user name" || parsed.Arguments[0] != "value of remote host" {
+		test.Errorf("unexpected result %q %v", asked, parsed)
+	}
+}
+
+func TestSpecDeprecated(test *testing.T) {
+	spec := Spec{
+		Name: "bot",
+		Options: []OptionSpec{
+			{Name: "channel", Description: "target channel"},
+			{Name: "room", Description: "target room", Deprecated: true, ReplacedBy: "channel"},
+			{Name: "debug", Type: BoolOption, Hidden: true},
+		},
+	}
+
+	parsed, err := spec.Parse("--room=general --debug")
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	if parsed.GetOption("channel", "") != "general" || !parsed.GetBoolOption("debug", false) {
+		test.Errorf("unexpected options %v", parsed.Options)
+	}
+
+	if len(parsed.Warnings) != 1 || parsed.Warnings[0] != "option --room is deprecated, use --channel instead" {
+		test.Errorf("unexpected warnings %q", parsed.Warnings)
+	}
+
+	if help := spec.Help(); strings.Contains(help, "debug") || !strings.Contains(help, "target room (deprecated)") {
+		test.Errorf("unexpected help %v", help)
+	}
+}
+
+func TestSpecExclusive(test *testing.T) {
+	spec := Spec{
+		Name: "dump",
+		Options: []OptionSpec{
+			{Name: "json", Type: BoolOption},
+			{Name: "yaml", Type: BoolOption},
+			{Name: "xml", Type: BoolOption},
+		},
+		Exclusive: [][]string{{"json", "yaml", "xml"}},
+	}
+
+	if _, err := spec.Parse("--json"); err != nil {
+		test.Error(err)
+	}
+
+	_, err := spec.Parse("--json --xml")
+	if !errors.Is(err, ErrConflictingOptions) || err.Error() != "mutually exclusive options: --json, --xml" {
+		test.Errorf("expected ErrConflictingOptions, got %v", err)
+	}
+}
+
+func TestSpecAttachedValues(test *testing.T) {
+	spec := Spec{
+		Name: "head",
+		Options: []OptionSpec{
+			{Name: "n", Type: IntOption},
+			{Name: "o"},
+			{Name: "v", Type: BoolOption},
+			{Name: "out"},
+		},
+	}
+
+	parsed, err := spec.Parse("-n42 -ofile.txt -vx -out=x --oops file")
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	expected := map[string]string{"n": "42", "o": "file.txt", "vx": "", "out": "x", "oops": ""}
+	if !reflect.DeepEqual(parsed.Options, expected) {
+		test.Errorf("expected %q got %q", expected, parsed.Options)
+	}
+
+	if _, _, explicit := parsed.Lookup("n"); !explicit {
+		test.Errorf("expected explicit value for -n")
+	}
+}
+
+func TestSpecAbbreviations(test *testing.T) {
+	spec := Spec{
+		Name: "calc",
+		Options: []OptionSpec{
+			{Name: "number", Type: IntOption},
+			{Name: "numeric", Type: BoolOption},
+			{Name: "verbose", Type: BoolOption},
+			{Name: "v", Type: BoolOption},
+		},
+		Abbreviations: true,
+	}
+
+	parsed, err := spec.Parse("--numb=42 --verb -v --other")
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	expected := map[string]string{"number": "42", "verbose": "", "v": "", "other": ""}
+	if !reflect.DeepEqual(parsed.Options, expected) {
+		test.Errorf("expected %q got %q", expected, parsed.Options)
+	}
+
+	_, err = spec.Parse("--num=1")
+	if !errors.Is(err, ErrAmbiguousOption) || err.Error() != "ambiguous option: --num could be --number, --numeric" {
+		test.Errorf("expected ErrAmbiguousOption, got %v", err)
+	}
+
+	// only long options are abbreviated
+	parsed, err = spec.Parse("-numb=42 -verb")
+	if expected := map[string]string{"numb": "42", "verb": ""}; err != nil || !reflect.DeepEqual(parsed.Options, expected) {
+		test.Errorf("expected %q got %q (%v)", expected, parsed.Options, err)
+	}
+
+	spec.Abbreviations = false
+	if parsed, _ := spec.Parse("--numb=42"); parsed.GetOption("numb", "") != "42" {
+		test.Errorf("unexpected abbreviation %q", parsed.Options)
+	}
+}
+
+func ExampleOptionSpec_group() {
+	spec := Spec{
+		Name: "fetch",
+		Options: []OptionSpec{
+			{Name: "json", Type: BoolOption, Description: "JSON output", Group: "Output options"},
+			{Name: "timeout", Type: IntOption, Description: "timeout in seconds", Group: "Network options"},
+			{Name: "v", Type: BoolOption, Description: "verbose"},
+			{Name: "yaml", Type: BoolOption, Description: "YAML output", Group: "Output options"},
+		},
+	}
+
+	fmt.Print(spec.Help())
+	// Output:
+	// Usage: fetch [options]
+	//
+	// Options:
+	//   -v             verbose
+	//
+	// Output options:
+	//   --json         JSON output
+	//   --yaml         YAML output
+	//
+	// Network options:
+	//   --timeout=int  timeout in seconds
+}
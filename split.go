@@ -0,0 +1,55 @@
+package args
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// ScanWords is a bufio.SplitFunc that returns each argument (as split by GetArgs) of the input,
+// so that a bufio.Scanner can be used for quote-aware splitting
+func ScanWords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	return splitWords(data, atEOF, nil)
+}
+
+// SplitFunc returns a bufio.SplitFunc like ScanWords, using the given options
+func SplitFunc(options ...GetArgsOption) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		return splitWords(data, atEOF, options)
+	}
+}
+
+func splitWords(data []byte, atEOF bool, options []GetArgsOption) (advance int, token []byte, err error) {
+	scanner := NewScanner(bytes.NewReader(data))
+	for _, option := range options {
+		option(scanner)
+	}
+
+	tok, err := scanner.Next()
+
+	//
+	// a token at the end of the buffer (or an unterminated quote, bracket or escape) may continue
+	// in the next block of data
+	//
+	incomplete := (err == nil && tok.End == EndEOF) || len(scanner.Diagnostics()) > 0 ||
+		(err == io.EOF && scanner.Comments != NoComments)
+
+	if !atEOF && (incomplete || (err != nil && err != io.EOF)) {
+		return 0, nil, nil
+	}
+
+	if err == io.EOF {
+		// only spaces (or comments) left
+		return len(data), nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if tok.Value == "" && !tok.Quoted {
+		// empty token before a user token
+		return scanner.offset, nil, nil
+	}
+
+	return scanner.offset, []byte(tok.Value), nil
+}
@@ -0,0 +1,48 @@
+package args
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScanWords(test *testing.T) {
+	input := "one \"two three\"  'four\nfive' six\\ seven {\"a\": [1, 2]}\n  eight \"\"  "
+	expected := []string{"one", "two three", "four\nfive", "six seven", `{"a": [1, 2]}`, "eight", ""}
+
+	for name, r := range map[string]*bufio.Scanner{
+		"full":     bufio.NewScanner(strings.NewReader(input)),
+		"one byte": bufio.NewScanner(iotest.OneByteReader(strings.NewReader(input))),
+	} {
+		r.Split(ScanWords)
+
+		words := []string{}
+		for r.Scan() {
+			words = append(words, r.Text())
+		}
+
+		if err := r.Err(); err != nil {
+			test.Errorf("%v: unexpected error %v", name, err)
+		}
+
+		if !reflect.DeepEqual(words, expected) {
+			test.Errorf("%v: expected %q got %q", name, expected, words)
+		}
+	}
+}
+
+func TestSplitFunc(test *testing.T) {
+	r := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(`a"b c"'d' "héllo wörld" # comment`)))
+	r.Split(SplitFunc(POSIXQuotes(), Concat(), Comments(WordStartComments)))
+
+	words := []string{}
+	for r.Scan() {
+		words = append(words, r.Text())
+	}
+
+	if expected := []string{"ab cd", "héllo wörld"}; !reflect.DeepEqual(words, expected) {
+		test.Errorf("expected %q got %q", expected, words)
+	}
+}
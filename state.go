@@ -0,0 +1,30 @@
+package args
+
+// State is a snapshot of the Scanner state, after the last token was read
+type State struct {
+	Quote    rune   // the character that closes the open quoted string (NO_QUOTE if none)
+	Brackets []rune // the closing brackets of the open bracketed sections (innermost last)
+	Escape   bool   // the input ended with an escape character
+	Offset   int    // number of bytes consumed
+	Line     int    // current line (1-based)
+}
+
+// Complete returns true if there are no open quotes, brackets or escapes,
+// i.e. the input doesn't need a continuation line
+func (s State) Complete() bool {
+	return s.Quote == NO_QUOTE && len(s.Brackets) == 0 && !s.Escape
+}
+
+// State returns the current state of the Scanner. At the end of the input it tells if the last token
+// was incomplete (unterminated quote or bracket, trailing escape), so that an interactive program can
+// show an indicator or ask for more input.
+func (scanner *Scanner) State() State {
+	state := scanner.state
+	if state.Quote == 0 {
+		state.Quote = NO_QUOTE
+	}
+
+	state.Offset = scanner.offset
+	state.Line = scanner.line + 1
+	return state
+}
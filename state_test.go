@@ -0,0 +1,37 @@
+package args
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestState(test *testing.T) {
+	tests := []struct {
+		line     string
+		expected State
+	}{
+		{`echo hello`, State{Quote: NO_QUOTE, Offset: 10, Line: 1}},
+		{`echo "hello`, State{Quote: '"', Offset: 11, Line: 1}},
+		{"echo 'a\nb", State{Quote: '\'', Offset: 9, Line: 2}},
+		{`echo {"a": [1`, State{Quote: NO_QUOTE, Brackets: []rune{'}', ']'}, Offset: 13, Line: 1}},
+		{`echo a\`, State{Quote: NO_QUOTE, Escape: true, Offset: 7, Line: 1}},
+	}
+
+	for _, t := range tests {
+		scanner := NewScannerString(t.line)
+		scanner.GetTokens()
+
+		state := scanner.State()
+		if !reflect.DeepEqual(state, t.expected) {
+			test.Errorf("%q: expected %+v got %+v", t.line, t.expected, state)
+		}
+
+		if complete := t.expected.Quote == NO_QUOTE && t.expected.Brackets == nil && !t.expected.Escape; state.Complete() != complete {
+			test.Errorf("%q: expected complete %v", t.line, complete)
+		}
+	}
+
+	if state := NewScannerString("").State(); !state.Complete() || state.Offset != 0 || state.Line != 1 {
+		test.Errorf("unexpected initial state %+v", state)
+	}
+}
@@ -0,0 +1,15 @@
+package args
+
+import "strings"
+
+// SubstitutionCommand returns the command inside a command substitution ($(cmd))
+// or a process substitution (<(cmd) or >(cmd)), so that it can be parsed in turn
+func SubstitutionCommand(value string) (cmd string, ok bool) {
+	for _, prefix := range []string{"$(", "<(", ">("} {
+		if strings.HasPrefix(value, prefix) && strings.HasSuffix(value, ")") {
+			return value[2 : len(value)-1], true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,76 @@
+package args
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSubstitution(test *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{`echo $(ls "a b") x`, []string{"echo", `$(ls "a b")`, "x"}},
+		{`a$(b (c) ")" ')' \)) d`, []string{`a$(b (c) ")" ')' \))`, "d"}},
+		{`echo $(a $(b c)) $HOME \$(x y)`, []string{"echo", "$(a $(b c))", "$HOME", "$(x", "y)"}},
+		{`"$(a b)" $(open`, []string{"$(a b)", "$(open"}},
+	}
+
+	for _, t := range tests {
+		if args := GetArgs(t.line); !reflect.DeepEqual(args, t.expected) {
+			test.Errorf("%q: expected %q got %q", t.line, t.expected, args)
+		}
+	}
+
+	if _, err := GetArgsStrict(`echo $(open`); !errors.Is(err, ErrUnbalancedBracket) {
+		test.Errorf("expected ErrUnbalancedBracket, got %v", err)
+	}
+
+	scanner := NewScannerString(`$(date +%s) x$(y)`)
+	for _, expected := range []TokenType{SubstitutionToken, WordToken} {
+		if tok, _ := scanner.Next(); tok.Type != expected {
+			test.Errorf("%q: expected %v got %v", tok.Value, expected, tok.Type)
+		}
+	}
+
+	if cmd, ok := SubstitutionCommand(`$(ls "a b")`); !ok || cmd != `ls "a b"` {
+		test.Errorf("unexpected command %q", cmd)
+	}
+	if _, ok := SubstitutionCommand("x"); ok {
+		test.Errorf("unexpected substitution")
+	}
+}
+
+func TestProcessSubstitution(test *testing.T) {
+	line := `diff <(sort "a b") >(tee log) <file x<(y)`
+
+	expected := []Token{
+		{Value: "diff", Type: WordToken},
+		{Value: `<(sort "a b")`, Type: SubstitutionToken},
+		{Value: ">(tee log)", Type: SubstitutionToken},
+		{Value: "<", Type: SymbolToken},
+		{Value: "file", Type: WordToken},
+		{Value: "x<(y)", Type: WordToken},
+	}
+
+	scanner := NewScannerString(line)
+	for _, e := range expected {
+		tok, err := scanner.Next()
+		if err != nil {
+			test.Fatal(err)
+		}
+
+		if tok.Value != e.Value || tok.Type != e.Type {
+			test.Errorf("expected %q %v got %q %v", e.Value, e.Type, tok.Value, tok.Type)
+		}
+	}
+
+	if cmd, ok := SubstitutionCommand(`<(sort "a b")`); !ok || !reflect.DeepEqual(GetArgs(cmd), []string{"sort", "a b"}) {
+		test.Errorf("unexpected command %q", cmd)
+	}
+
+	if args, expected := GetArgs("cat <(ls) <x>", AngleBrackets()), []string{"cat", "<(ls)", "<x>"}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+}
@@ -0,0 +1,72 @@
+package args
+
+import (
+	"io"
+	"strings"
+)
+
+// SYSTEMD_PREFIXES are the special executable prefixes recognized in systemd ExecStart= lines
+const SYSTEMD_PREFIXES = "@-:+!"
+
+// SystemdExec sets the scanner options that implement the systemd command line quoting rules:
+// single and double quotes, C-style escapes (including \s for space), no shell symbols or comments.
+// Specifiers (%n, %i, ...) and environment variables ($VAR, ${VAR}) are left intact.
+func SystemdExec() GetArgsOption {
+	return func(s *Scanner) {
+		s.Concat = true
+		s.CEscapes = true
+		s.NoSymbols = true
+		s.NoBrackets = true
+		s.ASCIISpaces = true
+		s.Comments = NoComments
+		s.Strict = true
+	}
+}
+
+// ExecCommand is a command line parsed from a systemd ExecStart= (or ExecStop=, ...) setting
+type ExecCommand struct {
+	Prefixes string   // special executable prefixes (i.e. "-" to ignore failures, "@" to set argv[0])
+	Path     string   // the executable path
+	Args     []string // the command arguments, starting with argv[0]
+}
+
+// ParseExecStart splits a systemd ExecStart= command line the way systemd does,
+// returning the executable prefixes, the executable path and the arguments.
+func ParseExecStart(line string) (cmd ExecCommand, err error) {
+	scanner := getScanner(line, SystemdExec())
+
+	args := []string{}
+
+	for {
+		tok, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return cmd, err
+		}
+
+		if len(args) == 0 && !tok.Quoted {
+			rest := strings.TrimLeft(tok.Value, SYSTEMD_PREFIXES)
+			cmd.Prefixes = tok.Value[:len(tok.Value)-len(rest)]
+			tok.Value = rest
+		}
+
+		args = append(args, tok.Value)
+	}
+
+	if len(args) == 0 {
+		return cmd, nil
+	}
+
+	cmd.Path = args[0]
+
+	if strings.Contains(cmd.Prefixes, "@") {
+		// the second word is argv[0]
+		cmd.Args = args[1:]
+	} else {
+		cmd.Args = args
+	}
+
+	return cmd, nil
+}
@@ -0,0 +1,29 @@
+package args
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseExecStart(test *testing.T) {
+	for line, expected := range map[string]ExecCommand{
+		`/bin/echo "hello world" 'single'\s'quotes' %n ${HOME}`: {"", "/bin/echo", []string{"/bin/echo", "hello world", "single quotes", "%n", "${HOME}"}},
+		`-@/usr/bin/daemon my-daemon --tab=\t --hex=\x41\101`:   {"-@", "/usr/bin/daemon", []string{"my-daemon", "--tab=\t", "--hex=AA"}},
+		`/bin/sh -c "echo a | grep a > /dev/null"`:              {"", "/bin/sh", []string{"/bin/sh", "-c", "echo a | grep a > /dev/null"}},
+		`/bin/echo {"a b"} [x`:                                  {"", "/bin/echo", []string{"/bin/echo", "{a b}", "[x"}},
+	} {
+		cmd, err := ParseExecStart(line)
+		if err != nil {
+			test.Error(err)
+		}
+
+		if !reflect.DeepEqual(cmd, expected) {
+			test.Errorf("%v: expected %q got %q", line, expected, cmd)
+		}
+	}
+
+	if _, err := ParseExecStart(`/bin/echo "unterminated`); !errors.Is(err, ErrUnterminatedQuote) {
+		test.Errorf("expected ErrUnterminatedQuote, got %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build tinygo
+
+package args
+
+// validateJSON doesn't validate the token: encoding/json is not used with TinyGo (see JSONBrackets)
+func (scanner *Scanner) validateJSON(value string, offset int) error {
+	return scanner.strictError()
+}
+
+// sprint formats the values of the types not handled by MapToArgs (strings, numbers, slices and maps are):
+// fmt is not used with TinyGo, so only fmt.Stringer and error values are formatted
+func sprint(value interface{}) string {
+	switch v := value.(type) {
+	case interface{ String() string }:
+		return v.String()
+	case error:
+		return v.Error()
+	}
+
+	return ""
+}
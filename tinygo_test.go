@@ -0,0 +1,42 @@
+//go:build !tinygo
+
+package args
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestTinyGoDeps(test *testing.T) {
+	gocmd, err := exec.LookPath("go")
+	if err != nil {
+		test.Skip("go command not found")
+	}
+
+	list := func(args ...string) []string {
+		out, err := exec.Command(gocmd, append([]string{"list", "-tags", "tinygo"}, args...)...).Output()
+		if err != nil {
+			test.Fatalf("go list %v: %v", args, err)
+		}
+
+		return strings.Fields(string(out))
+	}
+
+	denied := map[string]bool{
+		"encoding/gob": true, "encoding/json": true, "flag": true, "fmt": true, "log/slog": true,
+		"net/netip": true, "net/url": true, "os": true, "reflect": true, "regexp": true, "time": true,
+	}
+
+	for _, dep := range list("-deps", ".") {
+		if denied[dep] {
+			test.Errorf("unexpected dependency with the tinygo build tag: %v", dep)
+		}
+	}
+
+	for _, imp := range list("-f", `{{join .Imports " "}}`, ".") {
+		if imp == "runtime" {
+			test.Errorf("unexpected import with the tinygo build tag: %v", imp)
+		}
+	}
+}
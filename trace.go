@@ -0,0 +1,65 @@
+package args
+
+import "strconv"
+
+// TraceKind is the kind of a scanner state transition (see Scanner.Trace)
+type TraceKind int
+
+const (
+	TraceRune        TraceKind = iota // a rune was consumed
+	TraceQuoteOpen                    // a quote was opened
+	TraceQuoteClose                   // a quote was closed
+	TraceBracketPush                  // a bracket was opened
+	TraceBracketPop                   // a bracket was closed
+	TraceToken                        // a token was returned
+)
+
+func (k TraceKind) String() string {
+	switch k {
+	case TraceRune:
+		return "rune"
+	case TraceQuoteOpen:
+		return "quote open"
+	case TraceQuoteClose:
+		return "quote close"
+	case TraceBracketPush:
+		return "bracket push"
+	case TraceBracketPop:
+		return "bracket pop"
+	case TraceToken:
+		return "token"
+	}
+
+	return "TraceKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// TraceEvent describes a scanner state transition
+type TraceEvent struct {
+	Kind   TraceKind
+	Offset int    // byte offset of the rune (or of the end of the token, for TraceToken)
+	Rune   rune   // the rune (or the token delimiter, for TraceToken)
+	Depth  int    // bracket nesting level, after the transition
+	Value  string // the token value (only for TraceToken)
+}
+
+func (e TraceEvent) String() string {
+	if e.Kind == TraceToken {
+		return strconv.Itoa(e.Offset) + ": " + e.Kind.String() + " " + strconv.Quote(e.Value)
+	}
+
+	return strconv.Itoa(e.Offset) + ": " + e.Kind.String() + " " + strconv.QuoteRune(e.Rune) + " depth=" + strconv.Itoa(e.Depth)
+}
+
+// trace calls the Trace callback, if set
+func (scanner *Scanner) trace(kind TraceKind, offset int, c rune, depth int) {
+	if scanner.Trace != nil {
+		scanner.Trace(TraceEvent{Kind: kind, Offset: offset, Rune: c, Depth: depth})
+	}
+}
+
+// Trace calls f on every scanner state transition, to debug the tokenization of an input
+func Trace(f func(TraceEvent)) GetArgsOption {
+	return func(s *Scanner) {
+		s.Trace = f
+	}
+}
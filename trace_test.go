@@ -0,0 +1,46 @@
+package args
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTrace(test *testing.T) {
+	var events []string
+
+	GetArgs(`a "b" {[c]}`, Trace(func(e TraceEvent) {
+		if e.Kind != TraceRune {
+			events = append(events, e.String())
+		}
+	}))
+
+	expected := []string{
+		`2: token "a"`,
+		`2: quote open '"' depth=0`,
+		`4: quote close '"' depth=0`,
+		`5: token "b"`,
+		`6: bracket push '{' depth=1`,
+		`7: bracket push '[' depth=2`,
+		`9: bracket pop ']' depth=1`,
+		`10: bracket pop '}' depth=0`,
+		`11: token "{[c]}"`,
+	}
+
+	if !reflect.DeepEqual(events, expected) {
+		test.Errorf("expected %q got %q", expected, events)
+	}
+}
+
+func ExampleTrace() {
+	GetArgs(`say "hi"`, Trace(func(e TraceEvent) {
+		if e.Kind != TraceRune {
+			fmt.Println(e)
+		}
+	}))
+	// Output:
+	// 4: token "say"
+	// 4: quote open '"' depth=0
+	// 7: quote close '"' depth=0
+	// 8: token "hi"
+}
@@ -0,0 +1,84 @@
+package args
+
+import "path"
+
+// WrapperSpec describes a wrapper command, that runs another command (see Unwrap)
+type WrapperSpec struct {
+	ValueOptions []string // options that take the next argument as value (i.e. "-n" for nice)
+	Env          bool     // accepts NAME=value assignments before the command (as env)
+	Positional   int      // number of positional arguments before the command (i.e. the duration for timeout)
+}
+
+// WRAPPERS are the wrapper commands recognized by Unwrap
+var WRAPPERS = map[string]WrapperSpec{
+	"env":     {ValueOptions: []string{"-u", "--unset", "-C", "--chdir", "-S", "--split-string"}, Env: true},
+	"nohup":   {},
+	"nice":    {ValueOptions: []string{"-n", "--adjustment"}},
+	"sudo":    {ValueOptions: []string{"-u", "--user", "-g", "--group", "-h", "--host", "-p", "--prompt", "-C", "--close-from", "-U", "--other-user", "-r", "--role", "-t", "--type", "-D", "--chdir", "-R", "--chroot", "-T", "--command-timeout"}, Env: true},
+	"timeout": {ValueOptions: []string{"-s", "--signal", "-k", "--kill-after"}, Positional: 1},
+	"time":    {ValueOptions: []string{"-f", "--format", "-o", "--output"}},
+	"exec":    {ValueOptions: []string{"-a"}},
+	"command": {},
+	"stdbuf":  {ValueOptions: []string{"-i", "-o", "-e"}},
+	"ionice":  {ValueOptions: []string{"-c", "--class", "-n", "--classdata", "-p", "--pid"}},
+	"xargs":   {ValueOptions: []string{"-I", "-n", "-P", "-L", "-s", "-d", "-E", "-a"}},
+}
+
+// Unwrap peels the known wrapper commands (see WRAPPERS) off a command (i.e. sudo -u user nice -n 10 cmd args),
+// returning the wrappers (each with its options) and the innermost command.
+// If the command is only wrappers (i.e. "nohup"), cmd is empty.
+func Unwrap(args []string) (wrappers [][]string, cmd []string) {
+	wrappers = [][]string{}
+
+	for len(args) > 0 {
+		spec, ok := WRAPPERS[path.Base(args[0])]
+		if !ok {
+			break
+		}
+
+		n := spec.length(args)
+		wrappers = append(wrappers, args[:n])
+		args = args[n:]
+	}
+
+	return wrappers, args
+}
+
+// length returns the number of arguments that belong to the wrapper (including the wrapper name)
+func (spec WrapperSpec) length(args []string) int {
+	i := 1
+
+	for ; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			i++
+			break
+		}
+
+		if len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+
+		for _, opt := range spec.ValueOptions {
+			if arg == opt {
+				i++ // skip the value
+				break
+			}
+		}
+	}
+
+	if spec.Env {
+		for i < len(args) && isAssignment(args[i]) {
+			i++
+		}
+	}
+
+	i += spec.Positional
+
+	if i > len(args) {
+		i = len(args)
+	}
+
+	return i
+}
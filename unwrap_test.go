@@ -0,0 +1,26 @@
+package args
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnwrap(test *testing.T) {
+	for line, expected := range map[string][][]string{
+		`sudo -u admin -E nice -n 10 nohup /usr/bin/env -i FOO=bar timeout -s KILL 30 rm -rf /tmp/x`: {
+			{"sudo", "-u", "admin", "-E"}, {"nice", "-n", "10"}, {"nohup"}, {"/usr/bin/env", "-i", "FOO=bar"}, {"timeout", "-s", "KILL", "30"},
+			{"rm", "-rf", "/tmp/x"},
+		},
+		`ls -l`:                    {{"ls", "-l"}},
+		`nice -5 time -p make all`: {{"nice", "-5"}, {"time", "-p"}, {"make", "all"}},
+		`env -- -weird-cmd arg`:    {{"env", "--"}, {"-weird-cmd", "arg"}},
+		`timeout`:                  {{"timeout"}, {}},
+	} {
+		wrappers, cmd := Unwrap(GetArgs(line))
+		actual := append(wrappers, cmd)
+
+		if !reflect.DeepEqual(actual, expected) {
+			test.Errorf("%v: expected %q got %q", line, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package args
+
+import (
+	"strconv"
+	"strings"
+)
+
+// XARGS_PLACEHOLDER is the default placeholder replaced by Template.Expand (as in xargs -I {})
+const XARGS_PLACEHOLDER = "{}"
+
+// Template is a command line with placeholders, expanded with different values (see Expand)
+type Template struct {
+	Args        []string // the parsed command line
+	Placeholder string   // the placeholder (XARGS_PLACEHOLDER if empty)
+}
+
+// NewTemplate parses the command line into a Template, using the placeholder (XARGS_PLACEHOLDER if empty)
+func NewTemplate(line, placeholder string, options ...GetArgsOption) Template {
+	return Template{Args: GetArgs(line, options...), Placeholder: placeholder}
+}
+
+func (t Template) placeholder() string {
+	if t.Placeholder == "" {
+		return XARGS_PLACEHOLDER
+	}
+
+	return t.Placeholder
+}
+
+// Expand returns a copy of the arguments with every occurrence of the placeholder replaced by value.
+// Since the line is split before the replacement, the value is never split or unquoted,
+// even if it contains spaces or quotes. If no argument contains the placeholder, value is appended as the last argument.
+func (t Template) Expand(value string) []string {
+	p := t.placeholder()
+	args := make([]string, len(t.Args))
+	found := false
+
+	for i, arg := range t.Args {
+		if strings.Contains(arg, p) {
+			arg = strings.ReplaceAll(arg, p, value)
+			found = true
+		}
+
+		args[i] = arg
+	}
+
+	if !found {
+		args = append(args, value)
+	}
+
+	return args
+}
+
+// Batcher groups items in batches of arguments for a base command (as xargs), so that each command line
+// has at most MaxArgs items and MaxBytes total size.
+type Batcher struct {
+	Command  []string // the base command (included in each batch)
+	MaxArgs  int      // maximum number of items per batch (0 means no limit)
+	MaxBytes int      // maximum size of the command line in bytes, counting a terminating NUL per argument (0 means no limit)
+
+	// ArgOverhead is the number of bytes counted for each argument, in addition to the terminating NUL
+	// (i.e. the size of the argv pointer, see SplitCommand)
+	ArgOverhead int
+
+	// MaxArgBytes is the maximum size of a single item in bytes, including the terminating NUL (0 means no limit)
+	MaxArgBytes int
+
+	// ArgSize returns the size of an argument, counted for MaxBytes (nil counts the bytes, the terminating NUL
+	// and ArgOverhead)
+	ArgSize func(arg string) int
+
+	batch []string
+	size  int
+}
+
+// argSize returns the size of the argument as passed to exec (see ArgSize)
+func (b *Batcher) argSize(arg string) int {
+	if b.ArgSize != nil {
+		return b.ArgSize(arg)
+	}
+
+	return len(arg) + 1 + b.ArgOverhead
+}
+
+func (b *Batcher) commandSize() int {
+	size := 0
+	for _, arg := range b.Command {
+		size += b.argSize(arg)
+	}
+
+	return size
+}
+
+// Add adds an item to the current batch. If the item doesn't fit, the current batch is returned
+// (and a new batch is started with the item). It returns an ErrArgTooLong error if the item doesn't fit
+// in an empty batch.
+func (b *Batcher) Add(item string) ([]string, error) {
+	if b.batch == nil {
+		b.batch = append([]string{}, b.Command...)
+		b.size = b.commandSize()
+	}
+
+	if (b.MaxBytes > 0 && b.commandSize()+b.argSize(item) > b.MaxBytes) || (b.MaxArgBytes > 0 && len(item)+1 > b.MaxArgBytes) {
+		return nil, wrapDetail(ErrArgTooLong, strconv.Itoa(len(item))+" bytes")
+	}
+
+	var full []string
+
+	n := len(b.batch) - len(b.Command)
+	if (b.MaxArgs > 0 && n >= b.MaxArgs) || (b.MaxBytes > 0 && b.size+b.argSize(item) > b.MaxBytes) {
+		full = b.batch
+		b.batch = append([]string{}, b.Command...)
+		b.size = b.commandSize()
+	}
+
+	b.batch = append(b.batch, item)
+	b.size += b.argSize(item)
+	return full, nil
+}
+
+// Flush returns the current batch (nil if there are no items in it) and resets the Batcher
+func (b *Batcher) Flush() []string {
+	batch := b.batch
+	b.batch = nil
+	b.size = 0
+
+	if len(batch) <= len(b.Command) {
+		return nil
+	}
+
+	return batch
+}
+
+// Batches returns all the batches for the list of items
+func (b *Batcher) Batches(items []string) ([][]string, error) {
+	batches := [][]string{}
+
+	for _, item := range items {
+		batch, err := b.Add(item)
+		if err != nil {
+			return batches, err
+		}
+
+		if batch != nil {
+			batches = append(batches, batch)
+		}
+	}
+
+	if batch := b.Flush(); batch != nil {
+		batches = append(batches, batch)
+	}
+
+	return batches, nil
+}
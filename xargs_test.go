@@ -0,0 +1,73 @@
+package args
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTemplate(test *testing.T) {
+	t := NewTemplate(`cp "{}" '/backup/{}.bak'`, "")
+
+	if args, expected := t.Expand(`my "file".txt`), []string{"cp", `my "file".txt`, `/backup/my "file".txt.bak`}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+
+	t = NewTemplate(`rm -f`, "")
+	if args, expected := t.Expand("a b"), []string{"rm", "-f", "a b"}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+
+	t = NewTemplate(`echo %item% {}`, "%item%")
+	if args, expected := t.Expand("x"), []string{"echo", "x", "{}"}; !reflect.DeepEqual(args, expected) {
+		test.Errorf("expected %q got %q", expected, args)
+	}
+}
+
+func ExampleTemplate() {
+	t := NewTemplate(`convert {} -resize 50% "small/{}"`, "")
+
+	for _, file := range []string{"a.png", "my photo.png"} {
+		fmt.Printf("%q\n", t.Expand(file))
+	}
+	// Output:
+	// ["convert" "a.png" "-resize" "50%" "small/a.png"]
+	// ["convert" "my photo.png" "-resize" "50%" "small/my photo.png"]
+}
+
+func TestBatcher(test *testing.T) {
+	items := []string{"a", "bb", "ccc", "dddd", "e"}
+
+	b := Batcher{Command: []string{"rm", "-f"}, MaxArgs: 2}
+	batches, err := b.Batches(items)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	expected := [][]string{{"rm", "-f", "a", "bb"}, {"rm", "-f", "ccc", "dddd"}, {"rm", "-f", "e"}}
+	if !reflect.DeepEqual(batches, expected) {
+		test.Errorf("expected %q got %q", expected, batches)
+	}
+
+	// "rm" + "-f" = 6 bytes, leaving 8 bytes for the items
+	b = Batcher{Command: []string{"rm", "-f"}, MaxBytes: 14}
+	batches, err = b.Batches(items)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	expected = [][]string{{"rm", "-f", "a", "bb"}, {"rm", "-f", "ccc"}, {"rm", "-f", "dddd", "e"}}
+	if !reflect.DeepEqual(batches, expected) {
+		test.Errorf("expected %q got %q", expected, batches)
+	}
+
+	if _, err := b.Batches([]string{"toolongitem"}); !errors.Is(err, ErrArgTooLong) {
+		test.Errorf("expected ErrArgTooLong, got %v", err)
+	}
+
+	b = Batcher{Command: []string{"true"}}
+	if batches, _ := b.Batches(nil); len(batches) != 0 {
+		test.Errorf("expected no batches, got %q", batches)
+	}
+}